@@ -0,0 +1,471 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sohaha/zlsgo/zcli"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/zlsgo/office/xlsx"
+)
+
+var stdout io.Writer = os.Stdout
+
+// readFlags maps command line flags onto xlsx.ReadOptions
+type readFlags struct {
+	sheet      *string
+	headerMaps *string
+	fields     *string
+	rawFields  *string
+	calcFields *string
+	offsetX    *int
+	offsetY    *int
+	noHeader   *bool
+	trimSpace  *bool
+	removeRow  *bool
+}
+
+func (r *readFlags) register(fs *flag.FlagSet) {
+	r.sheet = fs.String("sheet", "", "sheet name, defaults to the first sheet")
+	r.offsetX = fs.Int("offset-x", 0, "skip the first N columns")
+	r.offsetY = fs.Int("offset-y", 0, "skip the first N rows")
+	r.noHeader = fs.Bool("no-header", false, "the first row is data, columns are keyed by letter")
+	r.headerMaps = fs.String("header-maps", "", "JSON file mapping header names to keys")
+	r.fields = fs.String("fields", "", "comma separated fields to read")
+	r.rawFields = fs.String("raw-fields", "", "comma separated fields to read as raw values")
+	r.calcFields = fs.String("calc-fields", "", "comma separated fields to read as calculated values")
+	r.trimSpace = fs.Bool("trim", false, "trim spaces of headers and values")
+	r.removeRow = fs.Bool("remove-empty", false, "remove empty rows")
+}
+
+func (r *readFlags) options() (func(*xlsx.ReadOptions), error) {
+	var headerMaps map[string]string
+	if *r.headerMaps != "" {
+		b, err := zfile.ReadFile(*r.headerMaps)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(b, &headerMaps); err != nil {
+			return nil, fmt.Errorf("invalid header maps file: %w", err)
+		}
+	}
+
+	return func(o *xlsx.ReadOptions) {
+		o.Sheet = *r.sheet
+		o.OffsetX = *r.offsetX
+		o.OffsetY = *r.offsetY
+		o.NoHeaderRow = *r.noHeader
+		o.HeaderMaps = headerMaps
+		o.Fields = splitList(*r.fields)
+		o.RawCellValueFields = splitList(*r.rawFields)
+		o.CalcCellValueFields = splitList(*r.calcFields)
+		o.TrimSpace = *r.trimSpace
+		o.RemoveEmptyRow = *r.removeRow
+	}, nil
+}
+
+// writeFlags maps command line flags onto xlsx.WriteOptions
+type writeFlags struct {
+	sheet *string
+	first *string
+	last  *string
+	bom   *bool
+}
+
+func (w *writeFlags) register(fs *flag.FlagSet) {
+	w.sheet = fs.String("out-sheet", "", "sheet name of the xlsx output")
+	w.first = fs.String("first", "", "comma separated fields placed first")
+	w.last = fs.String("last", "", "comma separated fields placed last")
+	w.bom = fs.Bool("bom", false, "write a UTF-8 BOM for csv or tsv output")
+}
+
+func (w *writeFlags) options() func(*xlsx.WriteOptions) {
+	return func(o *xlsx.WriteOptions) {
+		if *w.sheet != "" {
+			o.Sheet = *w.sheet
+		}
+		if first := splitList(*w.first); len(first) > 0 {
+			o.First = first
+		}
+		o.Last = splitList(*w.last)
+		o.BOM = *w.bom
+	}
+}
+
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	list := strings.Split(s, ",")
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+	return list
+}
+
+// parseArgs parses the remaining arguments allowing flags after positional ones
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func openWorkbook(path string) (*xlsx.Xlsx, error) {
+	if !zfile.FileExist(path) {
+		return nil, errors.New("file not found: " + path)
+	}
+	return xlsx.Open(path)
+}
+
+type sheetsCmd struct {
+	fs     *flag.FlagSet
+	asJSON *bool
+}
+
+func (c *sheetsCmd) Flags(sub *zcli.Subcommand) {
+	c.fs = sub.CommandLine
+	c.asJSON = c.fs.Bool("json", false, "print as JSON")
+}
+
+func (c *sheetsCmd) Run(args []string) {
+	zcli.CheckErr(c.run(args), true)
+}
+
+func (c *sheetsCmd) run(args []string) error {
+	args, err := parseArgs(c.fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("usage: office sheets [--json] <file.xlsx>")
+	}
+
+	f, err := openWorkbook(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sheets := f.Sheets()
+	if *c.asJSON {
+		return printJSON(sheets)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INDEX\tNAME\tVISIBLE\tDIMENSION")
+	for _, s := range sheets {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Index, s.Name, s.Visible, s.Dimension)
+	}
+	return w.Flush()
+}
+
+type headCmd struct {
+	fs     *flag.FlagSet
+	n      *int
+	format *string
+	read   readFlags
+}
+
+func (c *headCmd) Flags(sub *zcli.Subcommand) {
+	c.fs = sub.CommandLine
+	c.n = c.fs.Int("n", 10, "number of rows")
+	c.format = c.fs.String("format", xlsx.FormatMarkdown, "output format: markdown, csv, tsv, json, ndjson, html")
+	c.read.register(c.fs)
+}
+
+func (c *headCmd) Run(args []string) {
+	zcli.CheckErr(c.run(args), true)
+}
+
+func (c *headCmd) run(args []string) error {
+	args, err := parseArgs(c.fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("usage: office head [-n 10] [--sheet name] <file>")
+	}
+
+	readOpt, err := c.read.options()
+	if err != nil {
+		return err
+	}
+
+	header, data, err := xlsx.Load(args[0], "", readOpt, func(o *xlsx.ReadOptions) {
+		if *c.n > 0 {
+			o.MaxRows = *c.n
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return xlsx.Encode(stdout, *c.format, header, data)
+}
+
+type describeCmd struct {
+	fs     *flag.FlagSet
+	asJSON *bool
+	read   readFlags
+}
+
+func (c *describeCmd) Flags(sub *zcli.Subcommand) {
+	c.fs = sub.CommandLine
+	c.asJSON = c.fs.Bool("json", false, "print as JSON")
+	c.read.register(c.fs)
+}
+
+func (c *describeCmd) Run(args []string) {
+	zcli.CheckErr(c.run(args), true)
+}
+
+func (c *describeCmd) run(args []string) error {
+	args, err := parseArgs(c.fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("usage: office describe [--json] <file.xlsx>")
+	}
+
+	readOpt, err := c.read.options()
+	if err != nil {
+		return err
+	}
+
+	f, err := openWorkbook(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Describe(readOpt)
+	if err != nil {
+		return err
+	}
+	if *c.asJSON {
+		return printJSON(info)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for i, s := range info.Sheets {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "# %s (%s, %d rows, header row %d, %s)\n", s.Name, s.Dimension, s.Rows, s.HeaderRow, s.Visible)
+		if len(s.Columns) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, "COLUMN\tKEY\tTYPE\tNULL\tDISTINCT\tSAMPLES")
+		for _, col := range s.Columns {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.0f%%\t%d\t%s\n", col.Column, col.Key, col.Type, col.NullRatio*100, col.Distinct, strings.Join(col.Samples, ", "))
+		}
+	}
+	return w.Flush()
+}
+
+type convertCmd struct {
+	fs    *flag.FlagSet
+	from  *string
+	to    *string
+	read  readFlags
+	write writeFlags
+}
+
+func (c *convertCmd) Flags(sub *zcli.Subcommand) {
+	c.fs = sub.CommandLine
+	c.from = c.fs.String("from", "", "source format, detected from the extension by default")
+	c.to = c.fs.String("to", "", "target format, detected from the extension by default")
+	c.read.register(c.fs)
+	c.write.register(c.fs)
+}
+
+func (c *convertCmd) Run(args []string) {
+	zcli.CheckErr(c.run(args), true)
+}
+
+func (c *convertCmd) run(args []string) error {
+	args, err := parseArgs(c.fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return errors.New("usage: office convert <src> <dst>")
+	}
+
+	readOpt, err := c.read.options()
+	if err != nil {
+		return err
+	}
+
+	return xlsx.Convert(args[0], args[1], func(o *xlsx.ConvertOptions) {
+		o.From = *c.from
+		o.To = *c.to
+		o.Read = []func(*xlsx.ReadOptions){readOpt}
+		o.Write = []func(*xlsx.WriteOptions){c.write.options()}
+	})
+}
+
+type mergeCmd struct {
+	fs     *flag.FlagSet
+	out    *string
+	sheets *bool
+	read   readFlags
+	write  writeFlags
+}
+
+func (c *mergeCmd) Flags(sub *zcli.Subcommand) {
+	c.fs = sub.CommandLine
+	c.out = c.fs.String("o", "", "output file")
+	c.sheets = c.fs.Bool("sheets", false, "copy every sheet into the output workbook instead of appending rows")
+	c.read.register(c.fs)
+	c.write.register(c.fs)
+}
+
+func (c *mergeCmd) Run(args []string) {
+	zcli.CheckErr(c.run(args), true)
+}
+
+func (c *mergeCmd) run(args []string) error {
+	args, err := parseArgs(c.fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 || *c.out == "" {
+		return errors.New("usage: office merge <a> <b> [more...] -o <out>")
+	}
+
+	if *c.sheets {
+		return mergeSheets(args, *c.out)
+	}
+
+	readOpt, err := c.read.options()
+	if err != nil {
+		return err
+	}
+
+	var (
+		header []string
+		seen   = map[string]struct{}{}
+		rows   = ztype.Maps{}
+	)
+	for _, path := range args {
+		keys, data, err := xlsx.Load(path, "", readOpt)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, k := range keys {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				header = append(header, k)
+			}
+		}
+		rows = append(rows, data...)
+	}
+
+	return xlsx.Dump(*c.out, "", header, rows, c.write.options())
+}
+
+// mergeSheets copies every sheet of the workbooks into one, prefixing
+// duplicated sheet names with the source file name
+func mergeSheets(paths []string, out string) error {
+	dst, err := xlsx.Open("")
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	placeholder := dst.Sheets()[0].Name
+	names := map[string]struct{}{}
+	for _, path := range paths {
+		src, err := openWorkbook(path)
+		if err != nil {
+			return err
+		}
+
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		for _, s := range src.Sheets() {
+			name := uniqueSheetName(base, s.Name, names)
+			names[strings.ToLower(name)] = struct{}{}
+
+			if strings.EqualFold(name, placeholder) {
+				if err = dst.RenameSheet(placeholder, placeholder+"~"); err != nil {
+					_ = src.Close()
+					return err
+				}
+				placeholder += "~"
+			}
+			if err = src.CopySheet(s.Name, name, dst); err != nil {
+				_ = src.Close()
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		_ = src.Close()
+	}
+
+	if err = dst.DeleteSheet(placeholder); err != nil {
+		return err
+	}
+	return dst.SaveAs(out)
+}
+
+// uniqueSheetName returns the name of a merged sheet, prefixed with the base
+// name of its file when taken and then numbered until it is unique
+func uniqueSheetName(base, name string, taken map[string]struct{}) string {
+	free := func(n string) bool {
+		_, ok := taken[strings.ToLower(n)]
+		return !ok && xlsx.CheckSheetName(n) == nil
+	}
+	if free(name) {
+		return name
+	}
+
+	prefix := sanitizeSheetName(base + "-" + name)
+	name = prefix
+	for i := 2; !free(name); i++ {
+		suffix := "-" + strconv.Itoa(i)
+		name = sanitizeSheetName(truncateSheetName(prefix, xlsx.MaxSheetNameLength-len(suffix)) + suffix)
+	}
+	return name
+}
+
+// sanitizeSheetName replaces the characters not allowed in sheet names
+func sanitizeSheetName(name string) string {
+	name = strings.NewReplacer(":", "_", "\\", "_", "/", "_", "?", "_", "*", "_", "[", "(", "]", ")").Replace(name)
+	name = strings.Trim(truncateSheetName(name, xlsx.MaxSheetNameLength), "'")
+	if strings.TrimSpace(name) == "" {
+		return "Sheet"
+	}
+	return name
+}
+
+func truncateSheetName(name string, n int) string {
+	r := []rune(name)
+	if len(r) > n {
+		r = r[:n]
+	}
+	return string(r)
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/zcli"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func runCmd(t *testing.T, cmd interface {
+	Flags(*zcli.Subcommand)
+	run([]string) error
+}, args ...string,
+) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+
+	cmd.Flags(&zcli.Subcommand{CommandLine: flag.NewFlagSet("test", flag.ContinueOnError)})
+	err := cmd.run(args)
+	return buf.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	dir := t.TempDir()
+	a := dir + "/a.xlsx"
+	b := dir + "/b.csv"
+	tt.NoError(xlsx.WriteFile(a, ztype.Maps{
+		{"id": 1, "name": "张三"},
+		{"id": 2, "name": "李四"},
+	}, func(wo *xlsx.WriteOptions) {
+		wo.Sheet = "Users"
+		wo.First = []string{"id"}
+	}))
+	tt.NoError(xlsx.WriteCSVFile(b, ztype.Maps{{"id": 3, "name": "王五", "age": 20}}, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "name"}
+	}))
+
+	out, err := runCmd(t, &sheetsCmd{}, a)
+	tt.NoError(err)
+	tt.EqualTrue(strings.Contains(out, "Users"))
+	tt.EqualTrue(strings.Contains(out, "A1:B3"))
+
+	out, err = runCmd(t, &headCmd{}, "-n", "1", "--sheet", "Users", a)
+	tt.NoError(err)
+	tt.Equal("| id | name |\n|---|---|\n| 1 | 张三 |\n", out)
+
+	maps := dir + "/maps.json"
+	tt.NoError(zfile.WriteFile(maps, []byte(`{"name":"姓名"}`)))
+	out, err = runCmd(t, &headCmd{}, a, "--sheet", "Users", "--header-maps", maps, "--fields", "姓名", "--format", "csv")
+	tt.NoError(err)
+	tt.Equal("姓名\n张三\n李四\n", out)
+
+	out, err = runCmd(t, &describeCmd{}, "--sheet", "Users", a)
+	tt.NoError(err)
+	tt.EqualTrue(strings.Contains(out, "# Users"))
+	tt.EqualTrue(strings.Contains(out, "int"))
+
+	out, err = runCmd(t, &describeCmd{}, "--json", a)
+	tt.NoError(err)
+	tt.EqualTrue(strings.Contains(out, `"name": "Users"`))
+
+	dst := dir + "/out.json"
+	_, err = runCmd(t, &convertCmd{}, "--sheet", "Users", a, dst)
+	tt.NoError(err)
+	content, _ := zfile.ReadFile(dst)
+	tt.Equal("[\n{\"id\":\"1\",\"name\":\"张三\"},\n{\"id\":\"2\",\"name\":\"李四\"}\n]\n", string(content))
+
+	merged := dir + "/merged.csv"
+	_, err = runCmd(t, &mergeCmd{}, a, b, "-o", merged, "--sheet", "Users")
+	tt.NoError(err)
+	content, _ = zfile.ReadFile(merged)
+	tt.Equal("id,name,age\n1,张三,\n2,李四,\n3,王五,20\n", string(content))
+
+	src := dir + "/in.ndjson"
+	tt.NoError(zfile.WriteFile(src, []byte("{\"b\":1,\"a\":2}\n")))
+	dst = dir + "/out.csv"
+	_, err = runCmd(t, &convertCmd{}, "--bom", "--first", "a", src, dst)
+	tt.NoError(err)
+	content, _ = zfile.ReadFile(dst)
+	tt.Equal("\xEF\xBB\xBFa,b\n2,1\n", string(content))
+
+	dst = dir + "/out.tsv"
+	_, err = runCmd(t, &convertCmd{}, "--last", "b", "--bom", src, dst)
+	tt.NoError(err)
+	content, _ = zfile.ReadFile(dst)
+	tt.Equal("\xEF\xBB\xBFa\tb\n2\t1\n", string(content))
+
+	_, err = runCmd(t, &convertCmd{}, "--bom", src, dir+"/out.json")
+	tt.EqualTrue(err != nil)
+
+	merged = dir + "/merged.xlsx"
+	c := dir + "/c[1].xlsx"
+	content, _ = zfile.ReadFile(a)
+	tt.NoError(zfile.WriteFile(c, content))
+	_, err = runCmd(t, &mergeCmd{}, "--sheets", a, a, a, c, "-o", merged)
+	tt.NoError(err)
+	f, err := xlsx.Open(merged)
+	tt.NoError(err)
+	tt.Equal([]string{"Sheet1", "Users", "a-Sheet1", "a-Users", "a-Sheet1-2", "a-Users-2", "c(1)-Sheet1", "c(1)-Users"}, f.Engine().GetSheetList())
+	tt.Equal("李四", f.Get("a-Users", "B3").String())
+	_ = f.Close()
+
+	taken := map[string]struct{}{}
+	long := strings.Repeat("表", 40)
+	for _, want := range []string{"Users", long[:31*3], long[:29*3] + "-2"} {
+		name := uniqueSheetName(long, "Users", taken)
+		tt.Equal(want, name)
+		taken[strings.ToLower(name)] = struct{}{}
+	}
+
+	_, err = runCmd(t, &mergeCmd{}, a)
+	tt.EqualTrue(err != nil)
+	_, err = runCmd(t, &sheetsCmd{}, dir+"/not_exist.xlsx")
+	tt.EqualTrue(err != nil)
+	_, err = runCmd(t, &headCmd{}, "--header-maps", dir+"/not_exist.json", a)
+	tt.EqualTrue(err != nil)
+}
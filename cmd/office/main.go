@@ -0,0 +1,26 @@
+// Command office inspects and converts spreadsheets from the command line.
+//
+//	office sheets f.xlsx
+//	office head -n 20 --sheet X f.xlsx
+//	office describe f.xlsx
+//	office convert f.xlsx out.csv
+//	office merge a.xlsx b.xlsx -o c.xlsx
+package main
+
+import (
+	"github.com/sohaha/zlsgo/zcli"
+)
+
+func main() {
+	zcli.Name = "office"
+	zcli.Logo = "office - inspect and convert spreadsheets "
+	zcli.Version = "1.0.0"
+
+	zcli.Add("sheets", "List the sheets of a workbook", &sheetsCmd{})
+	zcli.Add("head", "Print the first rows of a sheet", &headCmd{})
+	zcli.Add("describe", "Report headers and inferred column types", &describeCmd{})
+	zcli.Add("convert", "Convert between xlsx, xls, ods, csv, tsv, json, ndjson, markdown and html", &convertCmd{})
+	zcli.Add("merge", "Merge the rows or sheets of several files into one", &mergeCmd{})
+
+	zcli.Run()
+}
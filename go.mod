@@ -3,12 +3,13 @@ module github.com/zlsgo/office
 go 1.23.0
 
 require (
+	github.com/richardlehane/mscfb v1.0.4
 	github.com/sohaha/zlsgo v1.7.19-0.20250611045820-0caa147b26e8
 	github.com/xuri/excelize/v2 v2.9.1
+	golang.org/x/text v0.25.0
 )
 
 require (
-	github.com/richardlehane/mscfb v1.0.4 // indirect
 	github.com/richardlehane/msoleps v1.0.4 // indirect
 	github.com/tiendc/go-deepcopy v1.6.0 // indirect
 	github.com/xuri/efp v0.0.1 // indirect
@@ -17,5 +18,4 @@ require (
 	golang.org/x/exp v0.0.0-20230213192124-5e25df0256eb // indirect
 	golang.org/x/net v0.40.0 // indirect
 	golang.org/x/sync v0.14.0 // indirect
-	golang.org/x/text v0.25.0 // indirect
 )
@@ -0,0 +1,162 @@
+package xlsx
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+)
+
+// Get returns the typed value of a cell, cell uses A1 notation
+func (x *Xlsx) Get(sheet, cell string) ztype.Type {
+	row, col, err := ParseCell(cell)
+	if err != nil {
+		return ztype.New(nil)
+	}
+	return ztype.New(x.cellValue(sheet, ToCell(row, col)))
+}
+
+// GetAt returns the typed value of a cell by 0-based row and column indexes
+func (x *Xlsx) GetAt(sheet string, row, col int) ztype.Type {
+	return ztype.New(x.cellValue(sheet, ToCell(row, col)))
+}
+
+// GetRange returns the typed values of a range such as A1:D10, row by row
+func (x *Xlsx) GetRange(sheet, ref string) [][]ztype.Type {
+	startRow, startCol, endRow, endCol, err := parseRange(ref)
+	if err != nil {
+		return nil
+	}
+
+	values := make([][]ztype.Type, 0, endRow-startRow+1)
+	for r := startRow; r <= endRow; r++ {
+		row := make([]ztype.Type, 0, endCol-startCol+1)
+		for c := startCol; c <= endCol; c++ {
+			row = append(row, ztype.New(x.cellValue(sheet, ToCell(r, c))))
+		}
+		values = append(values, row)
+	}
+	return values
+}
+
+// Set writes a value to a cell, time.Time, numbers and bools are stored as native Excel types
+func (x *Xlsx) Set(sheet, cell string, value interface{}) error {
+	row, col, err := ParseCell(cell)
+	if err != nil {
+		return err
+	}
+	return x.SetAt(sheet, row, col, value)
+}
+
+// SetAt writes a value to a cell by 0-based row and column indexes
+func (x *Xlsx) SetAt(sheet string, row, col int, value interface{}) error {
+	if row < 0 || col < 0 {
+		return errors.New("invalid cell index")
+	}
+
+	switch v := value.(type) {
+	case ztype.Type:
+		value = v.Value()
+	case *time.Time:
+		if v == nil {
+			value = nil
+		} else {
+			value = *v
+		}
+	}
+	return x.f.SetCellValue(sheet, ToCell(row, col), value)
+}
+
+// SetFormula writes a formula to a cell, the leading "=" is optional
+func (x *Xlsx) SetFormula(sheet, cell, formula string) error {
+	row, col, err := ParseCell(cell)
+	if err != nil {
+		return err
+	}
+	return x.f.SetCellFormula(sheet, ToCell(row, col), strings.TrimPrefix(formula, "="))
+}
+
+func (x *Xlsx) cellValue(sheet, cell string) interface{} {
+	raw, err := x.f.GetCellValue(sheet, cell, excelize.Options{RawCellValue: true})
+	if err != nil || raw == "" {
+		return nil
+	}
+
+	typ, _ := x.f.GetCellType(sheet, cell)
+	switch typ {
+	case excelize.CellTypeBool:
+		return raw == "1" || strings.EqualFold(raw, "TRUE")
+	case excelize.CellTypeDate:
+		if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			return t
+		}
+		return raw
+	case excelize.CellTypeSharedString, excelize.CellTypeInlineString, excelize.CellTypeFormula, excelize.CellTypeError:
+		return raw
+	}
+
+	n, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return raw
+	}
+
+	if x.isDateCell(sheet, cell) {
+		if t, err := excelize.ExcelDateToTime(n, x.date1904()); err == nil {
+			return t
+		}
+	}
+
+	if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+		return int64(n)
+	}
+	return n
+}
+
+func (x *Xlsx) isDateCell(sheet, cell string) bool {
+	styleID, err := x.f.GetCellStyle(sheet, cell)
+	if err != nil || styleID == 0 {
+		return false
+	}
+
+	style, err := x.f.GetStyle(styleID)
+	if err != nil {
+		return false
+	}
+
+	custom := ""
+	if style.CustomNumFmt != nil {
+		custom = *style.CustomNumFmt
+	}
+	return isDateNumFmt(style.NumFmt, custom)
+}
+
+func (x *Xlsx) date1904() bool {
+	props, err := x.f.GetWorkbookProps()
+	return err == nil && props.Date1904 != nil && *props.Date1904
+}
+
+func parseRange(ref string) (startRow, startCol, endRow, endCol int, err error) {
+	start, end, ok := strings.Cut(ref, ":")
+	if !ok {
+		end = start
+	}
+
+	if startRow, startCol, err = ParseCell(start); err != nil {
+		return
+	}
+	if endRow, endCol, err = ParseCell(end); err != nil {
+		return
+	}
+
+	if startRow > endRow {
+		startRow, endRow = endRow, startRow
+	}
+	if startCol > endCol {
+		startCol, endCol = endCol, startCol
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package xlsx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestCell(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	f, err := xlsx.Open("")
+	tt.NoError(err)
+	defer f.Close()
+
+	sheet := "Sheet1"
+	now := time.Date(2025, 3, 4, 10, 20, 30, 0, time.UTC)
+
+	tt.NoError(f.Set(sheet, "A1", "name"))
+	tt.NoError(f.Set(sheet, "b1", 123456))
+	tt.NoError(f.Set(sheet, "C1", 1.5))
+	tt.NoError(f.Set(sheet, "D1", true))
+	tt.NoError(f.SetAt(sheet, 1, 0, now))
+	tt.NoError(f.SetAt(sheet, 1, 1, "00123"))
+	tt.NoError(f.SetFormula(sheet, "C2", "=B1*2"))
+
+	tt.Equal("name", f.Get(sheet, "A1").String())
+	tt.Equal(123456, f.Get(sheet, "B1").Int())
+	tt.Equal(1.5, f.Get(sheet, "C1").Float64())
+	tt.Equal(true, f.GetAt(sheet, 0, 3).Bool())
+	tt.Equal("00123", f.Get(sheet, "B2").String())
+	tt.Equal(false, f.Get(sheet, "Z9").Exists())
+	tt.Equal(false, f.Get(sheet, "invalid").Exists())
+
+	date, ok := f.Get(sheet, "A2").Value().(time.Time)
+	tt.EqualTrue(ok)
+	tt.Equal(now.Unix(), date.Unix())
+
+	formula, err := f.Engine().GetCellFormula(sheet, "C2")
+	tt.NoError(err)
+	tt.Equal("B1*2", formula)
+
+	values := f.GetRange(sheet, "A1:D2")
+	tt.Equal(2, len(values))
+	tt.Equal(4, len(values[0]))
+	tt.Equal("name", values[0][0].String())
+	tt.Equal(true, values[0][3].Bool())
+	tt.Equal("00123", values[1][1].String())
+	tt.Equal(0, len(f.GetRange(sheet, "A:B")))
+
+	tt.EqualTrue(f.Set(sheet, "1A", 1) != nil)
+	tt.EqualTrue(f.Set("NotExist", "A1", 1) != nil)
+}
@@ -0,0 +1,137 @@
+package xlsx
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Chart types
+const (
+	ChartLine          = "line"
+	ChartColumn        = "column"
+	ChartColumnStacked = "columnStacked"
+	ChartBar           = "bar"
+	ChartBarStacked    = "barStacked"
+	ChartArea          = "area"
+	ChartAreaStacked   = "areaStacked"
+	ChartPie           = "pie"
+	ChartDoughnut      = "doughnut"
+	ChartScatter       = "scatter"
+	ChartRadar         = "radar"
+)
+
+var chartTypes = map[string]excelize.ChartType{
+	ChartLine:          excelize.Line,
+	ChartColumn:        excelize.Col,
+	ChartColumnStacked: excelize.ColStacked,
+	ChartBar:           excelize.Bar,
+	ChartBarStacked:    excelize.BarStacked,
+	ChartArea:          excelize.Area,
+	ChartAreaStacked:   excelize.AreaStacked,
+	ChartPie:           excelize.Pie,
+	ChartDoughnut:      excelize.Doughnut,
+	ChartScatter:       excelize.Scatter,
+	ChartRadar:         excelize.Radar,
+}
+
+// ChartSpec declares a chart over the written data by field names
+type ChartSpec struct {
+	// Type is line, column, columnStacked, bar, barStacked, area,
+	// areaStacked, pie, doughnut, scatter or radar, line by default
+	Type          string
+	Title         string
+	CategoryField string
+	ValueFields   []string
+	// Anchor is the top left cell of the chart, two columns to the right of
+	// the data by default
+	Anchor string
+	// Sheet places the chart alone on a new chart sheet of this name
+	Sheet string
+	// Size is the size of the chart in pixels, 480x260 by default
+	Size excelize.ChartDimension
+}
+
+// addCharts resolves the fields of the charts to the written ranges and adds them
+func addCharts(f *excelize.File, sheet string, header, labels []string, headerRows, dataRows int, o WriteOptions) error {
+	ref := "'" + strings.ReplaceAll(sheet, "'", "''") + "'!"
+	column := func(field string) (string, error) {
+		i := slices.Index(header, field)
+		if i < 0 {
+			return "", errors.New("chart references unknown field: " + field)
+		}
+		return "$" + ToCol(i) + "$", nil
+	}
+	first, last := strconv.Itoa(headerRows+1), strconv.Itoa(headerRows+max(dataRows, 1))
+
+	for n, spec := range o.Charts {
+		typ := spec.Type
+		if typ == "" {
+			typ = ChartLine
+		}
+		chartType, ok := chartTypes[typ]
+		if !ok {
+			return errors.New("unsupported chart type: " + typ)
+		}
+		if len(spec.ValueFields) == 0 {
+			return errors.New("chart has no value fields")
+		}
+
+		categories := ""
+		if spec.CategoryField != "" {
+			col, err := column(spec.CategoryField)
+			if err != nil {
+				return err
+			}
+			categories = ref + col + first + ":" + col + last
+		}
+
+		series := make([]excelize.ChartSeries, 0, len(spec.ValueFields))
+		for _, field := range spec.ValueFields {
+			col, err := column(field)
+			if err != nil {
+				return err
+			}
+			// the series is named after the header cell holding the leaf label
+			nameRow := 1
+			if o.HeaderSeparator != "" {
+				nameRow += strings.Count(labels[slices.Index(header, field)], o.HeaderSeparator)
+			}
+			series = append(series, excelize.ChartSeries{
+				Name:       ref + col + strconv.Itoa(nameRow),
+				Categories: categories,
+				Values:     ref + col + first + ":" + col + last,
+			})
+		}
+
+		chart := &excelize.Chart{
+			Type:      chartType,
+			Series:    series,
+			Dimension: spec.Size,
+			Legend:    excelize.ChartLegend{Position: "bottom"},
+		}
+		if spec.Title != "" {
+			chart.Title = []excelize.RichTextRun{{Text: spec.Title}}
+		}
+
+		if spec.Sheet != "" {
+			if err := f.AddChartSheet(spec.Sheet, chart); err != nil {
+				return err
+			}
+			continue
+		}
+
+		anchor := spec.Anchor
+		if anchor == "" {
+			// charts without an anchor are stacked to the right of the data
+			anchor = ToCol(len(header)+1) + strconv.Itoa(1+n*20)
+		}
+		if err := f.AddChart(sheet, anchor, chart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteCharts(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data := ztype.Maps{
+		{"month": "2025-01", "revenue": 100, "cost": 60},
+		{"month": "2025-02", "revenue": 120, "cost": 70},
+		{"month": "2025-03", "revenue": 90, "cost": 50},
+	}
+	b, err := xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"month", "revenue", "cost"}
+		wo.Charts = []xlsx.ChartSpec{
+			{Title: "趋势", CategoryField: "month", ValueFields: []string{"revenue", "cost"}},
+			{Type: xlsx.ChartColumn, CategoryField: "month", ValueFields: []string{"cost"}, Anchor: "F20", Size: excelize.ChartDimension{Width: 640, Height: 320}},
+			{Type: xlsx.ChartPie, CategoryField: "month", ValueFields: []string{"revenue"}, Sheet: "图表"},
+		}
+	})
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+	tt.Equal([]string{"Sheet1", "图表"}, f.GetSheetList())
+	tt.Equal("Sheet1", f.GetSheetName(f.GetActiveSheetIndex()))
+
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	tt.NoError(err)
+	charts := map[string]string{}
+	for _, file := range z.File {
+		if strings.HasPrefix(file.Name, "xl/charts/chart") {
+			r, err := file.Open()
+			tt.NoError(err)
+			c, _ := io.ReadAll(r)
+			r.Close()
+			charts[file.Name] = string(c)
+		}
+	}
+	tt.Equal(3, len(charts))
+	line := charts["xl/charts/chart1.xml"]
+	tt.EqualTrue(strings.Contains(line, "<lineChart>"))
+	tt.EqualTrue(strings.Contains(line, "&#39;Sheet1&#39;!$A$2:$A$4"))
+	tt.EqualTrue(strings.Contains(line, "&#39;Sheet1&#39;!$B$2:$B$4"))
+	tt.EqualTrue(strings.Contains(line, "&#39;Sheet1&#39;!$C$1"))
+	tt.EqualTrue(strings.Contains(line, "趋势"))
+	tt.EqualTrue(strings.Contains(charts["xl/charts/chart2.xml"], `<barDir val="col"`))
+	tt.EqualTrue(strings.Contains(charts["xl/charts/chart3.xml"], "<pieChart>"))
+
+	_, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.Charts = []xlsx.ChartSpec{{ValueFields: []string{"profit"}}}
+	})
+	tt.EqualTrue(err != nil)
+	_, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.Charts = []xlsx.ChartSpec{{Type: "gauge", ValueFields: []string{"cost"}}}
+	})
+	tt.EqualTrue(err != nil)
+}
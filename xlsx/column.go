@@ -0,0 +1,212 @@
+package xlsx
+
+import (
+	"strings"
+
+	"github.com/sohaha/zlsgo/zarray"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/width"
+)
+
+// Bounds and sample size used by AutoWidth
+const (
+	DefaultMinWidth = 8.0
+	DefaultMaxWidth = 60.0
+
+	autoWidthSample  = 1000
+	autoWidthPadding = 2
+)
+
+// ColumnSpec declares the header label and format of a written column
+type ColumnSpec struct {
+	// Style is the base style of the column, NumFmt, Align and Wrap are applied on top of it
+	Style  *excelize.Style
+	Field  string
+	Label  string
+	NumFmt string
+	// Align is the horizontal alignment: left, center or right
+	Align  string
+	Width  float64
+	Wrap   bool
+	Hidden bool
+	// Locked keeps the column read-only when the sheet is protected
+	Locked bool
+}
+
+func (c ColumnSpec) style() *excelize.Style {
+	if c.Style == nil && c.NumFmt == "" && c.Align == "" && !c.Wrap {
+		return nil
+	}
+
+	style := &excelize.Style{}
+	if c.Style != nil {
+		s := *c.Style
+		style = &s
+	}
+	if c.NumFmt != "" {
+		numFmt := c.NumFmt
+		style.CustomNumFmt = &numFmt
+	}
+	if c.Align != "" || c.Wrap {
+		alignment := excelize.Alignment{}
+		if style.Alignment != nil {
+			alignment = *style.Alignment
+		}
+		if c.Align != "" {
+			alignment.Horizontal = c.Align
+		}
+		if c.Wrap {
+			alignment.WrapText = true
+		}
+		style.Alignment = &alignment
+	}
+	return style
+}
+
+func columnSpecs(o WriteOptions) map[string]ColumnSpec {
+	specs := make(map[string]ColumnSpec, len(o.Columns))
+	for _, c := range o.Columns {
+		specs[c.Field] = c
+	}
+	return specs
+}
+
+// columnFields returns the fields placed first, Columns only orders the
+// fields when First is empty so that styling never moves columns
+func columnFields(o WriteOptions) []string {
+	if len(o.First) > 0 {
+		return o.First
+	}
+	fields := make([]string, 0, len(o.Columns))
+	for _, c := range o.Columns {
+		if !zarray.Contains(fields, c.Field) && !zarray.Contains(o.Last, c.Field) {
+			fields = append(fields, c.Field)
+		}
+	}
+	return fields
+}
+
+// headerLabels returns the header text of every field
+func headerLabels(header []string, o WriteOptions) []string {
+	if len(o.Columns) == 0 {
+		return header
+	}
+
+	specs := columnSpecs(o)
+	labels := make([]string, len(header))
+	for i := range header {
+		labels[i] = header[i]
+		if spec, ok := specs[header[i]]; ok && spec.Label != "" {
+			labels[i] = spec.Label
+		}
+	}
+	return labels
+}
+
+// applyColumns sets the width, visibility and style of the declared columns
+// once per column, cells written afterwards inherit the column style. On
+// protected sheets every column carries its locked state
+func applyColumns(f *excelize.File, sheet string, header []string, o WriteOptions, styles *numFmtStyles) error {
+	if len(o.Columns) == 0 && o.Protect == nil {
+		return nil
+	}
+
+	specs := columnSpecs(o)
+	for i := range header {
+		spec, ok := specs[header[i]]
+		if !ok && o.Protect == nil {
+			continue
+		}
+
+		col := ToCol(i)
+		style := spec.style()
+		if o.Protect != nil {
+			style = protectStyle(style, spec.Locked)
+		}
+		if style != nil {
+			id, err := f.NewStyle(style)
+			if err != nil {
+				return err
+			}
+			if err = f.SetColStyle(sheet, col, id); err != nil {
+				return err
+			}
+			styles.setColumn(i, style, spec.NumFmt != "")
+		}
+		if spec.Width > 0 {
+			if err := f.SetColWidth(sheet, col, col, spec.Width); err != nil {
+				return err
+			}
+		}
+		if spec.Hidden {
+			if err := f.SetColVisible(sheet, col, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// textWidth returns the display width of the longest line of the text,
+// East Asian wide and fullwidth characters count as two columns
+func textWidth(s string) int {
+	longest := 0
+	for _, line := range strings.Split(s, "\n") {
+		n := 0
+		for _, r := range line {
+			switch width.LookupRune(r).Kind() {
+			case width.EastAsianWide, width.EastAsianFullwidth:
+				n += 2
+			default:
+				n++
+			}
+		}
+		longest = max(longest, n)
+	}
+	return longest
+}
+
+// autoWidth fits the columns to the header labels and the formatted text
+// of the first data rows, columns with a declared width are left untouched
+func autoWidth(f *excelize.File, sheet string, header, labels []string, headerRows, dataRows int, o WriteOptions) error {
+	minWidth, maxWidth := o.MinWidth, o.MaxWidth
+	if minWidth <= 0 {
+		minWidth = DefaultMinWidth
+	}
+	if maxWidth <= 0 {
+		maxWidth = DefaultMaxWidth
+	}
+
+	widths := make([]int, len(header))
+	for i := range labels {
+		// groups span several columns, only the leaf of the label is measured
+		label := labels[i]
+		if n := strings.LastIndex(label, o.HeaderSeparator); o.HeaderSeparator != "" && n >= 0 {
+			label = label[n+len(o.HeaderSeparator):]
+		}
+		widths[i] = textWidth(label)
+	}
+	for r := headerRows + 1; r <= headerRows+min(dataRows, autoWidthSample); r++ {
+		for i := range header {
+			// formatted values, so number formats such as thousands separators are measured
+			text, err := f.GetCellValue(sheet, ToCell(r-1, i))
+			if err != nil {
+				return err
+			}
+			widths[i] = max(widths[i], textWidth(text))
+		}
+	}
+
+	specs := columnSpecs(o)
+	for i := range header {
+		if spec, ok := specs[header[i]]; ok && (spec.Width > 0 || spec.Hidden) {
+			continue
+		}
+		w := min(max(float64(widths[i]+autoWidthPadding), minWidth), maxWidth)
+		col := ToCol(i)
+		if err := f.SetColWidth(sheet, col, col, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteColumns(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_columns.xlsx"
+	defer os.Remove(testFile)
+
+	day := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+	data := ztype.Maps{
+		{"id": 1, "name": "张三", "price": 1234.5, "rate": 0.25, "day": day, "remark": "long text", "secret": "x"},
+		{"id": 2, "name": "李四", "price": 8, "rate": 0.5, "day": day, "remark": nil, "secret": "y"},
+	}
+	tt.NoError(xlsx.WriteFile(testFile, data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "name", "price", "rate", "day", "note", "remark", "secret"}
+		wo.Columns = []xlsx.ColumnSpec{
+			{Field: "name", Label: "姓名", Width: 20, Style: &excelize.Style{Font: &excelize.Font{Bold: true}}},
+			{Field: "price", Label: "金额", NumFmt: "#,##0.00", Align: "right"},
+			{Field: "rate", NumFmt: "0.0%"},
+			{Field: "day", Align: "center"},
+			{Field: "note", Label: "备注"},
+			{Field: "remark", Wrap: true, Width: 40},
+			{Field: "secret", Hidden: true},
+		}
+	}))
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+	e := f.Engine()
+
+	header, err := e.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal([]string{"id", "姓名", "金额", "rate", "day", "备注", "remark", "secret"}, header[0])
+	tt.Equal([]string{"1", "张三", "1,234.50", "25.0%", "2025-03-08", "", "long text", "x"}, header[1])
+
+	width, err := e.GetColWidth("Sheet1", "B")
+	tt.NoError(err)
+	tt.Equal(20.0, width)
+	width, _ = e.GetColWidth("Sheet1", "G")
+	tt.Equal(40.0, width)
+	visible, err := e.GetColVisible("Sheet1", "H")
+	tt.NoError(err)
+	tt.EqualTrue(!visible)
+
+	style := func(cell string) *excelize.Style {
+		id, err := e.GetCellStyle("Sheet1", cell)
+		tt.NoError(err)
+		s, err := e.GetStyle(id)
+		tt.NoError(err)
+		return s
+	}
+	tt.EqualTrue(style("B3").Font.Bold)
+	tt.Equal("right", style("C3").Alignment.Horizontal)
+	tt.Equal("center", style("E2").Alignment.Horizontal)
+	tt.Equal(xlsx.DefaultDateFormat, *style("E2").CustomNumFmt)
+	tt.EqualTrue(style("G2").Alignment.WrapText)
+
+	rows, err := f.Read()
+	tt.NoError(err)
+	tt.Equal(2, len(rows))
+	tt.Equal("李四", rows[1].Get("姓名").String())
+	tt.Equal("8.00", rows[1].Get("金额").String())
+	tt.Equal("50.0%", rows[1].Get("rate").String())
+
+	b, err := xlsx.WriteCSV(data, func(wo *xlsx.WriteOptions) {
+		wo.Columns = []xlsx.ColumnSpec{{Field: "name", Label: "姓名"}}
+		wo.Last = []string{"secret"}
+	})
+	tt.NoError(err)
+	tt.Equal("姓名,day,id,price,rate,remark,secret", string(b[:len("姓名,day,id,price,rate,remark,secret")]))
+
+	// styling columns does not move them once First is set
+	b, err = xlsx.WriteCSV(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id"}
+		wo.Columns = []xlsx.ColumnSpec{{Field: "remark", Label: "备注"}, {Field: "name", Label: "姓名"}}
+	})
+	tt.NoError(err)
+	tt.Equal("id,day,姓名,price,rate,备注,secret", string(b[:len("id,day,姓名,price,rate,备注,secret")]))
+}
+
+func TestWriteAutoWidth(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data := ztype.Maps{
+		{"id": 1, "备注说明": "短", "price": 1234567.5, "remark": strings.Repeat("长", 100), "name": "x"},
+		{"id": 2, "备注说明": "第二行\n多行文本内容", "price": 1, "remark": "", "name": "y"},
+	}
+	b, err := xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.AutoWidth = true
+		wo.First = []string{"id", "备注说明", "price", "remark"}
+		wo.Columns = []xlsx.ColumnSpec{
+			{Field: "price", NumFmt: "#,##0.00"},
+			{Field: "name", Width: 20},
+		}
+	})
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+
+	widths := map[string]float64{}
+	for _, col := range []string{"A", "B", "C", "D", "E"} {
+		widths[col], err = f.GetColWidth("Sheet1", col)
+		tt.NoError(err)
+	}
+	tt.Equal(xlsx.DefaultMinWidth, widths["A"])
+	tt.Equal(14.0, widths["B"])
+	tt.Equal(14.0, widths["C"])
+	tt.Equal(xlsx.DefaultMaxWidth, widths["D"])
+	tt.Equal(20.0, widths["E"])
+
+	b, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.AutoWidth = true
+		wo.MinWidth = 4
+		wo.MaxWidth = 30
+		wo.First = []string{"id", "备注说明", "price", "remark"}
+	})
+	tt.NoError(err)
+	f, err = excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+	width, _ := f.GetColWidth("Sheet1", "A")
+	tt.Equal(4.0, width)
+	width, _ = f.GetColWidth("Sheet1", "D")
+	tt.Equal(30.0, width)
+}
@@ -0,0 +1,164 @@
+package xlsx
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Conditional format types
+const (
+	ConditionalCell       = "cell"
+	ConditionalFormula    = "formula"
+	ConditionalColorScale = "colorScale"
+	ConditionalDataBar    = "dataBar"
+	ConditionalIconSet    = "iconSet"
+	ConditionalDuplicate  = "duplicate"
+	ConditionalUnique     = "unique"
+	ConditionalTop        = "top"
+	ConditionalBottom     = "bottom"
+)
+
+// Default colors of color scales and data bars
+const (
+	DefaultScaleMinColor = "#F8696B"
+	DefaultScaleMidColor = "#FFEB84"
+	DefaultScaleMaxColor = "#63BE7B"
+	DefaultBarColor      = "#638EC6"
+	DefaultIconStyle     = "3Arrows"
+)
+
+// ConditionalFormat highlights the data cells of a column
+type ConditionalFormat struct {
+	// Style is the format of matching cells for cell, formula, duplicate,
+	// unique, top and bottom rules
+	Style *excelize.Style
+	// Type is cell, formula, colorScale, dataBar, iconSet, duplicate, unique,
+	// top or bottom
+	Type string
+	// Criteria compares cell values: >, >=, <, <=, =, !=, between or not between
+	Criteria string
+	// Value is the operand of the cell criteria, or the rank of top and bottom
+	Value string
+	// MinValue and MaxValue are the operands of between criteria
+	MinValue string
+	MaxValue string
+	// Formula is the expression of formula rules, {cell} stands for the
+	// first data cell of the column, e.g. AND({cell}<>"",{cell}<TODAY())
+	Formula string
+	// MinColor, MidColor and MaxColor are the colors of a color scale,
+	// the scale has three colors when MidColor is set
+	MinColor string
+	MidColor string
+	MaxColor string
+	BarColor string
+	// IconStyle is the icon set such as 3Arrows, 3TrafficLights1 or 5Rating
+	IconStyle    string
+	Percent      bool
+	ReverseIcons bool
+	StopIfTrue   bool
+}
+
+func (c ConditionalFormat) options(f *excelize.File, cell string) (excelize.ConditionalFormatOptions, error) {
+	opt := excelize.ConditionalFormatOptions{Criteria: "=", StopIfTrue: c.StopIfTrue}
+	switch c.Type {
+	case ConditionalCell:
+		if c.Criteria == "" {
+			return opt, errors.New("conditional format criteria is required")
+		}
+		opt.Type, opt.Criteria = "cell", c.Criteria
+		opt.Value, opt.MinValue, opt.MaxValue = c.Value, c.MinValue, c.MaxValue
+	case ConditionalFormula:
+		if c.Formula == "" {
+			return opt, errors.New("conditional format formula is required")
+		}
+		opt.Type = "formula"
+		opt.Criteria = strings.ReplaceAll(strings.TrimPrefix(c.Formula, "="), "{cell}", cell)
+	case ConditionalDuplicate, ConditionalUnique:
+		opt.Type = c.Type
+	case ConditionalTop, ConditionalBottom:
+		opt.Type, opt.Value, opt.Percent = c.Type, c.Value, c.Percent
+	case ConditionalColorScale:
+		opt.Type = "2_color_scale"
+		opt.MinType, opt.MaxType = "min", "max"
+		opt.MinColor, opt.MaxColor = c.MinColor, c.MaxColor
+		if opt.MinColor == "" {
+			opt.MinColor = DefaultScaleMinColor
+		}
+		if opt.MaxColor == "" {
+			opt.MaxColor = DefaultScaleMaxColor
+		}
+		if c.MidColor != "" {
+			opt.Type = "3_color_scale"
+			opt.MidType, opt.MidColor = "percentile", c.MidColor
+		}
+		return opt, nil
+	case ConditionalDataBar:
+		opt.Type = "data_bar"
+		opt.MinType, opt.MaxType = "min", "max"
+		opt.BarColor = c.BarColor
+		if opt.BarColor == "" {
+			opt.BarColor = DefaultBarColor
+		}
+		return opt, nil
+	case ConditionalIconSet:
+		opt.Type = "icon_set"
+		opt.IconStyle, opt.ReverseIcons = c.IconStyle, c.ReverseIcons
+		if opt.IconStyle == "" {
+			opt.IconStyle = DefaultIconStyle
+		}
+		return opt, nil
+	default:
+		return opt, errors.New("unsupported conditional format type: " + c.Type)
+	}
+
+	if c.Style != nil {
+		style, err := f.NewConditionalStyle(c.Style)
+		if err != nil {
+			return opt, err
+		}
+		opt.Format = &style
+	}
+	return opt, nil
+}
+
+// applyConditionalFormats adds the conditional formats of the fields to the
+// data rows of their columns
+func applyConditionalFormats(f *excelize.File, sheet string, header []string, headerRows, dataRows int, o WriteOptions) error {
+	if len(o.ConditionalFormats) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(o.ConditionalFormats))
+	for field := range o.ConditionalFormats {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		i := slices.Index(header, field)
+		if i < 0 {
+			continue
+		}
+
+		col := ToCol(i)
+		cell := col + strconv.Itoa(headerRows+1)
+		opts := make([]excelize.ConditionalFormatOptions, 0, len(o.ConditionalFormats[field]))
+		for _, c := range o.ConditionalFormats[field] {
+			opt, err := c.options(f, cell)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, opt)
+		}
+		ref := cell + ":" + col + strconv.Itoa(headerRows+max(dataRows, 1))
+		if err := f.SetConditionalFormat(sheet, ref, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
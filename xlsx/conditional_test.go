@@ -0,0 +1,77 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteConditionalFormats(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	day := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+	data := ztype.Maps{
+		{"due": day, "balance": -10, "score": 90, "code": "a", "rate": 0.1},
+		{"due": day, "balance": 20, "score": 60, "code": "a", "rate": 0.5},
+		{"due": day, "balance": 30, "score": 70, "code": "b", "rate": 0.9},
+	}
+	red := &excelize.Style{Font: &excelize.Font{Color: "#9C0006"}}
+	b, err := xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"due", "balance", "score", "code", "rate"}
+		wo.ConditionalFormats = map[string][]xlsx.ConditionalFormat{
+			"due": {{Type: xlsx.ConditionalFormula, Formula: `AND({cell}<>"",{cell}<TODAY())`, Style: red}},
+			"balance": {
+				{Type: xlsx.ConditionalCell, Criteria: "<", Value: "0", Style: red},
+				{Type: xlsx.ConditionalDataBar},
+			},
+			"score": {{Type: xlsx.ConditionalTop, Value: "1", Style: red}, {Type: xlsx.ConditionalIconSet, IconStyle: "3TrafficLights1"}},
+			"code":  {{Type: xlsx.ConditionalDuplicate, Style: red}},
+			"rate":  {{Type: xlsx.ConditionalColorScale, MidColor: xlsx.DefaultScaleMidColor}},
+			"none":  {{Type: xlsx.ConditionalUnique}},
+		}
+	})
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+
+	formats, err := f.GetConditionalFormats("Sheet1")
+	tt.NoError(err)
+	tt.Equal(5, len(formats))
+
+	due := formats["A2:A4"]
+	tt.Equal(1, len(due))
+	tt.Equal("formula", due[0].Type)
+	tt.Equal(`AND(A2<>"",A2<TODAY())`, due[0].Criteria)
+	tt.EqualTrue(due[0].Format != nil)
+
+	balance := formats["B2:B4"]
+	tt.Equal(2, len(balance))
+	tt.Equal("cell", balance[0].Type)
+	tt.Equal("0", balance[0].Value)
+	tt.Equal("data_bar", balance[1].Type)
+
+	score := formats["C2:C4"]
+	tt.Equal("top", score[0].Type)
+	tt.Equal("1", score[0].Value)
+	tt.Equal("icon_set", score[1].Type)
+	tt.Equal("3TrafficLights1", score[1].IconStyle)
+
+	tt.Equal("duplicate", formats["D2:D4"][0].Type)
+	tt.Equal("3_color_scale", formats["E2:E4"][0].Type)
+
+	_, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.ConditionalFormats = map[string][]xlsx.ConditionalFormat{"score": {{Type: "unknown"}}}
+	})
+	tt.EqualTrue(err != nil)
+	_, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.ConditionalFormats = map[string][]xlsx.ConditionalFormat{"score": {{Type: xlsx.ConditionalCell}}}
+	})
+	tt.EqualTrue(err != nil)
+}
@@ -0,0 +1,407 @@
+package xlsx
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sohaha/zlsgo/zarray"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/sohaha/zlsgo/zutil"
+	"github.com/xuri/excelize/v2"
+)
+
+// Formats supported by Convert
+const (
+	FormatXLSX     = "xlsx"
+	FormatXLS      = "xls"
+	FormatODS      = "ods"
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+var formatExts = map[string]string{
+	".xlsx":     FormatXLSX,
+	".xlsm":     FormatXLSX,
+	".xltx":     FormatXLSX,
+	".xltm":     FormatXLSX,
+	".xls":      FormatXLS,
+	".ods":      FormatODS,
+	".csv":      FormatCSV,
+	".tsv":      FormatTSV,
+	".tab":      FormatTSV,
+	".json":     FormatJSON,
+	".ndjson":   FormatNDJSON,
+	".jsonl":    FormatNDJSON,
+	".md":       FormatMarkdown,
+	".markdown": FormatMarkdown,
+	".html":     FormatHTML,
+	".htm":      FormatHTML,
+}
+
+type ConvertOptions struct {
+	From  string
+	To    string
+	Read  []func(*ReadOptions)
+	Write []func(*WriteOptions)
+}
+
+func errUnsupportedFormat(format string) error {
+	return errors.New("unsupported format: " + format)
+}
+
+// FormatOf returns the format of a file by its extension
+func FormatOf(path string) string {
+	return formatExts[strings.ToLower(filepath.Ext(path))]
+}
+
+// DetectFormat returns the format of an existing file by its magic bytes,
+// falling back to the extension
+func DetectFormat(path string) string {
+	format := FormatOf(path)
+	path = zfile.RealPath(path)
+	if isOLEFile(path) {
+		if format != FormatXLSX {
+			return FormatXLS
+		}
+	}
+	if isODSFile(path) {
+		return FormatODS
+	}
+	return format
+}
+
+// Convert converts src to dst, the formats are taken from the file extensions
+// unless ConvertOptions.From/To are set. Column order follows the source.
+// Rows are written as they are read: csv, tsv, json and ndjson sources are
+// streamed into csv, tsv, json, ndjson, markdown, html and xlsx outputs. json
+// sources are read twice, once to collect the keys of every object for the
+// header. Spreadsheet sources and ods outputs keep every row in memory
+func Convert(src, dst string, opt ...func(*ConvertOptions)) error {
+	o := zutil.Optional(ConvertOptions{}, opt...)
+	if o.From == "" {
+		o.From = DetectFormat(src)
+	}
+	if o.To == "" {
+		o.To = FormatOf(dst)
+	}
+
+	rows, err := openRows(src, o.From, zutil.Optional(ReadOptions{}, o.Read...))
+	if err != nil {
+		return err
+	}
+	defer rows.close()
+
+	return dumpRows(dst, o.To, rows, o.Write...)
+}
+
+// dumpRows writes the rows to a file as Dump does while they are read, the
+// file is created once the first row is read. xlsx outputs go through
+// StreamWriter unless they need options it cannot apply
+func dumpRows(path, format string, rows *rowIterator, opt ...func(*WriteOptions)) error {
+	o := zutil.Optional(WriteOptions{}, opt...)
+	stream := format == FormatXLSX && checkStreamOptions(o) == nil
+	if !stream && format != FormatXLSX && format != FormatODS {
+		if _, err := newEncoder(format, io.Discard, o); err != nil {
+			return err
+		}
+		stream = true
+	}
+
+	first, err := rows.next()
+	if err == io.EOF {
+		return errors.New("no data")
+	}
+	if err != nil {
+		return err
+	}
+
+	if !stream {
+		data := ztype.Maps{first}
+		for {
+			row, err := rows.next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			data = append(data, row)
+		}
+		return Dump(path, format, rows.header, data, opt...)
+	}
+
+	header := zarray.SortWithPriority(rows.header, o.First, o.Last)
+	if format == FormatXLSX {
+		x := &Xlsx{f: excelize.NewFile()}
+		defer x.Close()
+		s, err := x.NewStreamWriter(header, append(opt, func(wo *WriteOptions) {
+			wo.First = header
+		})...)
+		if err != nil {
+			return err
+		}
+		for row := first; err == nil; row, err = rows.next() {
+			if err = s.Write(row); err != nil {
+				return err
+			}
+		}
+		if err != io.EOF {
+			return err
+		}
+		if err = s.Flush(); err != nil {
+			return err
+		}
+		return x.f.SaveAs(zfile.RealPath(path))
+	}
+
+	f, err := os.Create(zfile.RealPath(path))
+	if err != nil {
+		return err
+	}
+	if err = encodeRows(f, format, header, first, rows, o); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return f.Close()
+}
+
+// encodeRows encodes the first row and the rest of the rows to w
+func encodeRows(w io.Writer, format string, header []string, first ztype.Map, rows *rowIterator, o WriteOptions) error {
+	enc, err := newEncoder(format, w, o)
+	if err != nil {
+		return err
+	}
+	if err = enc.WriteHeader(header); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(header))
+	for row := first; err == nil; row, err = rows.next() {
+		if err = encodeRow(enc, header, row, values, o); err != nil {
+			return err
+		}
+	}
+	if err != io.EOF {
+		return err
+	}
+	return enc.Close()
+}
+
+// Dump writes the rows to a file of the given format (detected from the
+// extension when empty), columns are written in header order unless
+// WriteOptions.First/Last move them. Options that the format cannot apply
+// are rejected.
+func Dump(path, format string, header []string, data ztype.Maps, opt ...func(*WriteOptions)) error {
+	if format == "" {
+		format = FormatOf(path)
+	}
+
+	if format == FormatXLSX || format == FormatODS {
+		if len(data) > 0 {
+			// the columns come from the keys of the first row, which is copied
+			// so the missing keys are not added to the data of the caller
+			first := make(ztype.Map, len(header))
+			for _, k := range header {
+				first[k] = nil
+			}
+			for k, v := range data[0] {
+				first[k] = v
+			}
+			data = append(ztype.Maps{first}, data[1:]...)
+		}
+		o := zutil.Optional(WriteOptions{}, opt...)
+		header = zarray.SortWithPriority(header, o.First, o.Last)
+		opt = append(opt[:len(opt):len(opt)], func(wo *WriteOptions) {
+			wo.First = header
+		})
+		if format == FormatODS {
+			b, err := WriteODS(data, opt...)
+			if err != nil {
+				return err
+			}
+			return zfile.WriteFile(path, b)
+		}
+		return WriteFile(path, data, opt...)
+	}
+
+	if _, err := newEncoder(format, io.Discard, zutil.Optional(WriteOptions{}, opt...)); err != nil {
+		return err
+	}
+
+	f, err := os.Create(zfile.RealPath(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = Encode(f, format, header, data, opt...); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads a file of the given format (detected from the extension when
+// empty) and returns the column keys in source order along with every row,
+// Convert streams the rows instead
+func Load(path, format string, opt ...func(*ReadOptions)) ([]string, ztype.Maps, error) {
+	if format == "" {
+		format = DetectFormat(path)
+	}
+	o := zutil.Optional(ReadOptions{}, opt...)
+
+	switch format {
+	case FormatXLSX, FormatXLS, FormatODS:
+		if !zfile.FileExist(path) {
+			return nil, nil, errors.New("file not found: " + path)
+		}
+		f, err := Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		return f.read(o)
+	case FormatCSV, FormatTSV:
+		if format == FormatTSV && o.Delimiter == 0 {
+			o.Delimiter = '\t'
+		}
+		b, err := zfile.ReadFile(zfile.RealPath(path))
+		if err != nil {
+			return nil, nil, err
+		}
+		return readCSV(b, o)
+	case FormatJSON, FormatNDJSON:
+		f, err := os.Open(zfile.RealPath(path))
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		return decodeJSONRows(bufio.NewReader(f))
+	}
+	return nil, nil, errUnsupportedFormat(format)
+}
+
+// decodeJSONRows decodes a JSON array of objects or newline delimited objects,
+// keeping the key order of the objects
+func decodeJSONRows(r io.Reader) ([]string, ztype.Maps, error) {
+	var (
+		header []string
+		seen   = make(map[string]struct{})
+		data   = ztype.Maps{}
+		dec    = newJSONRowDecoder(r)
+	)
+	for {
+		row, keys, err := dec.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range keys {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				header = append(header, key)
+			}
+		}
+		data = append(data, row)
+	}
+
+	if len(data) == 0 {
+		return nil, data, errors.New("no data")
+	}
+	return header, data, nil
+}
+
+// jsonRowDecoder decodes the objects of a JSON array or of newline
+// delimited JSON one at a time
+type jsonRowDecoder struct {
+	dec   *json.Decoder
+	array bool
+	rows  int
+}
+
+func newJSONRowDecoder(r io.Reader) *jsonRowDecoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &jsonRowDecoder{dec: dec}
+}
+
+// next returns the next object along with its keys in order, io.EOF at the end
+func (d *jsonRowDecoder) next() (ztype.Map, []string, error) {
+	for {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch tok {
+		case json.Delim('['):
+			if d.array || d.rows > 0 {
+				return nil, nil, errors.New("json rows must be objects")
+			}
+			d.array = true
+			continue
+		case json.Delim(']'):
+			if !d.array {
+				return nil, nil, errors.New("invalid json rows")
+			}
+			d.array = false
+			continue
+		case json.Delim('{'):
+		default:
+			return nil, nil, errors.New("json rows must be objects")
+		}
+
+		row, keys := ztype.Map{}, []string{}
+		for d.dec.More() {
+			tok, err = d.dec.Token()
+			if err != nil {
+				return nil, nil, err
+			}
+			key, _ := tok.(string)
+
+			var value interface{}
+			if err = d.dec.Decode(&value); err != nil {
+				return nil, nil, err
+			}
+			if _, ok := row[key]; !ok {
+				keys = append(keys, key)
+			}
+			row[key] = jsonValue(value)
+		}
+		if _, err = d.dec.Token(); err != nil {
+			return nil, nil, err
+		}
+		d.rows++
+		return row, keys, nil
+	}
+}
+
+func jsonValue(v interface{}) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	// integers beyond int64 are kept as text to avoid losing digits
+	if !strings.ContainsAny(n.String(), ".eE") {
+		return n.String()
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
@@ -0,0 +1,259 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestConvert(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	src := "./testdata/test_convert.xlsx"
+	defer os.Remove(src)
+
+	f, err := xlsx.Open("")
+	tt.NoError(err)
+	header := []string{"name", "age", "remark"}
+	tt.NoError(f.Engine().SetSheetRow("Sheet1", "A1", &header))
+	tt.NoError(f.Engine().SetSheetRow("Sheet1", "A2", &[]interface{}{"张三", 18, "a|b"}))
+	tt.NoError(f.Engine().SetSheetRow("Sheet1", "A3", &[]interface{}{"李四", 20, "<x>"}))
+	tt.NoError(f.Engine().SaveAs(src))
+	_ = f.Close()
+
+	cases := map[string]string{
+		"test_convert.csv":    "name,age,remark\n张三,18,a|b\n李四,20,<x>\n",
+		"test_convert.json":   "[\n{\"name\":\"张三\",\"age\":\"18\",\"remark\":\"a|b\"},\n{\"name\":\"李四\",\"age\":\"20\",\"remark\":\"\\u003cx\\u003e\"}\n]\n",
+		"test_convert.ndjson": "{\"name\":\"张三\",\"age\":\"18\",\"remark\":\"a|b\"}\n{\"name\":\"李四\",\"age\":\"20\",\"remark\":\"\\u003cx\\u003e\"}\n",
+		"test_convert.md":     "| name | age | remark |\n|---|---|---|\n| 张三 | 18 | a\\|b |\n| 李四 | 20 | <x> |\n",
+	}
+	for name, want := range cases {
+		dst := "./testdata/" + name
+		tt.NoError(xlsx.Convert(src, dst))
+		b, err := zfile.ReadFile(dst)
+		tt.NoError(err)
+		tt.Equal(want, string(b))
+		_ = os.Remove(dst)
+	}
+
+	dst := "./testdata/test_convert.html"
+	defer os.Remove(dst)
+	tt.NoError(xlsx.Convert(src, dst))
+	b, err := zfile.ReadFile(dst)
+	tt.NoError(err)
+	tt.EqualTrue(strings.Contains(string(b), "<th>name</th><th>age</th><th>remark</th>"))
+	tt.EqualTrue(strings.Contains(string(b), "<td>&lt;x&gt;</td>"))
+
+	tt.EqualTrue(xlsx.Convert(src, "./testdata/test_convert.unknown") != nil)
+	tt.EqualTrue(xlsx.Convert(src, "./testdata/test_convert.pdf", func(co *xlsx.ConvertOptions) {
+		co.To = "pdf"
+	}) != nil)
+}
+
+func TestConvertToXlsx(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	src := "./testdata/test_convert_rows.ndjson"
+	dst := "./testdata/test_convert_rows.xlsx"
+	defer os.Remove(src)
+	defer os.Remove(dst)
+
+	tt.NoError(zfile.WriteFile(src, []byte("{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\",\"extra\":true}\n")))
+	tt.NoError(xlsx.Convert(src, dst))
+
+	header, data, err := xlsx.Load(dst, "")
+	tt.NoError(err)
+	tt.Equal([]string{"id", "name", "extra"}, header)
+	tt.Equal(2, len(data))
+	tt.Equal(2, data[1].Get("id").Int())
+	tt.Equal("TRUE", data[1].Get("extra").String())
+
+	tt.NoError(zfile.WriteFile(src, []byte(`[{"b":1.5,"a":"x"},{"b":12345678901234567890}]`)))
+	header, data, err = xlsx.Load(src, xlsx.FormatJSON)
+	tt.NoError(err)
+	tt.Equal([]string{"b", "a"}, header)
+	tt.Equal(1.5, data[0].Get("b").Float64())
+	tt.Equal("12345678901234567890", data[1].Get("b").String())
+
+	tt.NoError(zfile.WriteFile(src, []byte(`[1,2]`)))
+	_, _, err = xlsx.Load(src, xlsx.FormatJSON)
+	tt.EqualTrue(err != nil)
+}
+
+func TestEncode(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	var buf bytes.Buffer
+	data := ztype.Maps{{"a": 1, "b": nil}}
+	tt.NoError(xlsx.Encode(&buf, xlsx.FormatTSV, []string{"b", "a"}, data))
+	tt.Equal("b\ta\n\t1\n", buf.String())
+
+	buf.Reset()
+	tt.NoError(xlsx.Encode(&buf, xlsx.FormatCSV, []string{"b", "a", "c"}, data, func(o *xlsx.WriteOptions) {
+		o.First = []string{"a"}
+		o.Last = []string{"b"}
+		o.BOM = true
+		o.Delimiter = ';'
+		o.NilValue = "-"
+	}))
+	tt.Equal("\xEF\xBB\xBFa;c;b\n1;-;-\n", buf.String())
+
+	err := xlsx.Encode(&buf, xlsx.FormatJSON, []string{"a"}, data, func(o *xlsx.WriteOptions) {
+		o.BOM = true
+	})
+	tt.Equal("json output does not support WriteOptions.BOM", err.Error())
+	err = xlsx.Encode(&buf, xlsx.FormatTSV, []string{"a"}, data, func(o *xlsx.WriteOptions) {
+		o.Delimiter = ';'
+	})
+	tt.EqualTrue(err != nil)
+
+	xlsx.RegisterEncoder("count", func(w io.Writer) xlsx.Encoder {
+		return &countEncoder{w: w}
+	})
+	buf.Reset()
+	tt.NoError(xlsx.Encode(&buf, "count", []string{"a"}, ztype.Maps{{"a": 1}, {"a": 2}}))
+	tt.Equal("2", buf.String())
+}
+
+func TestDumpOptions(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	dst := "./testdata/test_dump_options.csv"
+	defer os.Remove(dst)
+
+	data := ztype.Maps{{"b": 1, "a": 2}}
+	tt.NoError(xlsx.Dump(dst, "", []string{"b", "a"}, data, func(o *xlsx.WriteOptions) {
+		o.First = []string{"a"}
+		o.BOM = true
+	}))
+	b, err := zfile.ReadFile(dst)
+	tt.NoError(err)
+	tt.Equal("\xEF\xBB\xBFa,b\n2,1\n", string(b))
+
+	tt.NoError(os.Remove(dst))
+	err = xlsx.Dump(dst, "", []string{"b", "a"}, data, func(o *xlsx.WriteOptions) {
+		o.AutoWidth = true
+	})
+	tt.Equal("csv output does not support WriteOptions.AutoWidth", err.Error())
+	tt.EqualTrue(!zfile.FileExist(dst))
+
+	xlsxDst := "./testdata/test_dump_options.xlsx"
+	defer os.Remove(xlsxDst)
+	data = ztype.Maps{{"b": 1, "a": 2}}
+	tt.NoError(xlsx.Dump(xlsxDst, "", []string{"b", "a", "c"}, data, func(o *xlsx.WriteOptions) {
+		o.Last = []string{"b"}
+	}))
+	header, _, err := xlsx.Load(xlsxDst, "")
+	tt.NoError(err)
+	tt.Equal([]string{"a", "c", "b"}, header)
+	tt.Equal(2, len(data[0]))
+}
+
+func TestConvertStream(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	src := "./testdata/test_convert_stream.csv"
+	dst := "./testdata/test_convert_stream.ndjson"
+	want := "./testdata/test_convert_stream_want.ndjson"
+	defer os.Remove(src)
+	defer os.Remove(dst)
+	defer os.Remove(want)
+
+	tt.NoError(zfile.WriteFile(src, []byte("\xEF\xBB\xBFreport;;\n id; name ;note\n1; a ;x\n;;\n2;b\n3;c;z\n;\n\n")))
+	for _, opt := range []func(*xlsx.ReadOptions){
+		func(ro *xlsx.ReadOptions) { ro.OffsetY = 1 },
+		func(ro *xlsx.ReadOptions) {
+			ro.OffsetY = 1
+			ro.TrimSpace = true
+			ro.HeaderMaps = map[string]string{"name": "姓名"}
+			ro.RemoveEmptyRow = true
+			ro.MaxRows = 3
+		},
+		func(ro *xlsx.ReadOptions) {
+			ro.OffsetY = 1
+			ro.OffsetX = 1
+			ro.Fields = []string{"note"}
+		},
+	} {
+		tt.NoError(xlsx.Convert(src, dst, func(co *xlsx.ConvertOptions) {
+			co.Read = []func(*xlsx.ReadOptions){opt}
+		}))
+		header, data, err := xlsx.Load(src, "", opt)
+		tt.NoError(err)
+		tt.NoError(xlsx.Dump(want, "", header, data))
+
+		got, err := zfile.ReadFile(dst)
+		tt.NoError(err)
+		expected, err := zfile.ReadFile(want)
+		tt.NoError(err)
+		tt.Equal(string(expected), string(got))
+	}
+
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String("姓名,年龄\n张三,18\n")
+	tt.NoError(err)
+	tt.NoError(zfile.WriteFile(src, []byte(gbk)))
+	tt.NoError(xlsx.Convert(src, dst))
+	b, err := zfile.ReadFile(dst)
+	tt.NoError(err)
+	tt.Equal("{\"姓名\":\"张三\",\"年龄\":\"18\"}\n", string(b))
+
+	// xlsx outputs are streamed with the header presets
+	xlsxDst := "./testdata/test_convert_stream.xlsx"
+	defer os.Remove(xlsxDst)
+	tt.NoError(zfile.WriteFile(dst, []byte("{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"extra\":true}\n")))
+	tt.NoError(xlsx.Convert(dst, xlsxDst, func(co *xlsx.ConvertOptions) {
+		co.Write = []func(*xlsx.WriteOptions){func(wo *xlsx.WriteOptions) {
+			wo.FreezeHeader = true
+			wo.Last = []string{"id"}
+		}}
+	}))
+	f, err := excelize.OpenFile(xlsxDst)
+	tt.NoError(err)
+	defer f.Close()
+	panes, err := f.GetPanes("Sheet1")
+	tt.NoError(err)
+	tt.EqualTrue(panes.Freeze)
+	rows, err := f.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal([][]string{{"name", "extra", "id"}, {"a", "", "1"}, {"", "TRUE", "2"}}, rows)
+
+	// options that need every row fall back to writing the whole data
+	tt.NoError(xlsx.Convert(dst, xlsxDst, func(co *xlsx.ConvertOptions) {
+		co.Write = []func(*xlsx.WriteOptions){func(wo *xlsx.WriteOptions) { wo.AutoWidth = true }}
+	}))
+	header, _, err := xlsx.Load(xlsxDst, "")
+	tt.NoError(err)
+	tt.Equal([]string{"id", "name", "extra"}, header)
+
+	tt.NoError(os.Remove(dst))
+	tt.NoError(zfile.WriteFile(src, []byte("a,b\n,\n")))
+	tt.Equal("no data", xlsx.Convert(src, dst).Error())
+	tt.EqualTrue(!zfile.FileExist(dst))
+}
+
+type countEncoder struct {
+	w io.Writer
+	n int
+}
+
+func (e *countEncoder) WriteHeader([]string) error { return nil }
+
+func (e *countEncoder) WriteRow([]interface{}) error {
+	e.n++
+	return nil
+}
+
+func (e *countEncoder) Close() error {
+	_, err := e.w.Write([]byte(strconv.Itoa(e.n)))
+	return err
+}
@@ -0,0 +1,221 @@
+package xlsx
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/sohaha/zlsgo/zutil"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ReadCSV read csv or tsv file
+func ReadCSV(path string, opt ...func(*ReadOptions)) (ztype.Maps, error) {
+	b, err := zfile.ReadFile(zfile.RealPath(path))
+	if err != nil {
+		return nil, err
+	}
+	_, data, err := readCSV(b, zutil.Optional(ReadOptions{}, opt...))
+	return data, err
+}
+
+// ReadCSVFrom read csv or tsv data from reader
+func ReadCSVFrom(r io.Reader, opt ...func(*ReadOptions)) (ztype.Maps, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, data, err := readCSV(b, zutil.Optional(ReadOptions{}, opt...))
+	return data, err
+}
+
+func readCSV(b []byte, o ReadOptions) ([]string, ztype.Maps, error) {
+	rows, err := parseCSV(b, o.Delimiter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	minRows := o.OffsetY + 2
+	if o.NoHeaderRow {
+		minRows = o.OffsetY + 1
+	}
+	if len(rows) < minRows {
+		return nil, ztype.Maps{}, errors.New("no data")
+	}
+
+	cols, _, rows := parseHeader(rows, o)
+
+	dataStartRowNum := o.OffsetY + 1
+	if !o.NoHeaderRow {
+		dataStartRowNum = o.OffsetY + 2
+	}
+
+	rowsMeta := make([]rowMeta, len(rows))
+	for i, row := range rows {
+		rowsMeta[i] = rowMeta{row: row, rowNum: dataStartRowNum + i}
+	}
+
+	data := mapRows(rowsMeta, cols, o, func(meta rowMeta, j int, _ string) string {
+		return meta.row[o.OffsetX+j]
+	})
+	return columnKeys(cols, data, o), data, nil
+}
+
+// parseCSV decodes the data into rows, trailing empty cells and rows are
+// dropped so that the result matches what GetRows returns for a sheet
+func parseCSV(b []byte, delimiter rune) ([][]string, error) {
+	b = decodeText(b)
+	if delimiter == 0 {
+		delimiter = detectDelimiter(b)
+	}
+
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	last := -1
+	for i := range rows {
+		n := len(rows[i])
+		for n > 0 && rows[i][n-1] == "" {
+			n--
+		}
+		rows[i] = rows[i][:n]
+		if n > 0 {
+			last = i
+		}
+	}
+	return rows[:last+1], nil
+}
+
+// decodeText strips the UTF-8 BOM and converts GBK/GB18030 content to UTF-8
+func decodeText(b []byte) []byte {
+	b = bytes.TrimPrefix(b, utf8BOM)
+	if utf8.Valid(b) {
+		return b
+	}
+
+	decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(b)
+	if err != nil {
+		return b
+	}
+	return decoded
+}
+
+// detectDelimiter picks the candidate that splits the first lines into the
+// same number of fields, preferring the one that yields the most fields
+func detectDelimiter(b []byte) rune {
+	if len(b) > 64<<10 {
+		b = b[:64<<10]
+	}
+	parts := strings.SplitN(string(b), "\n", 11)
+	if len(parts) == 11 {
+		parts = parts[:10]
+	}
+
+	lines := make([]string, 0, len(parts))
+	for _, line := range parts {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	best, bestCount, bestConsistent := ',', 0, false
+	for _, d := range []rune{',', '\t', ';', '|'} {
+		count, consistent := -1, true
+		for i, line := range lines {
+			n := countDelimiter(line, d)
+			if i == 0 {
+				count = n
+			} else if n != count {
+				consistent = false
+			}
+		}
+		if count <= 0 {
+			continue
+		}
+		if (consistent && !bestConsistent) || (consistent == bestConsistent && count > bestCount) {
+			best, bestCount, bestConsistent = d, count, consistent
+		}
+	}
+	return best
+}
+
+func countDelimiter(line string, d rune) int {
+	n, quoted := 0, false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == d && !quoted:
+			n++
+		}
+	}
+	return n
+}
+
+// WriteCSV write csv data, use WriteOptions.Delimiter for tsv
+func WriteCSV(data ztype.Maps, opt ...func(*WriteOptions)) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no data")
+	}
+
+	o := zutil.Optional(WriteOptions{}, opt...)
+	header := sortHeader(data[0], o)
+
+	var buf bytes.Buffer
+	if o.BOM {
+		buf.Write(utf8BOM)
+	}
+
+	w := csv.NewWriter(&buf)
+	if o.Delimiter != 0 {
+		w.Comma = o.Delimiter
+	}
+
+	if err := w.Write(headerLabels(header, o)); err != nil {
+		return nil, err
+	}
+
+	record := make([]string, len(header))
+	for i := range data {
+		for j := range header {
+			v, ok := data[i][header[j]]
+			if !ok || v == nil {
+				record[j] = ztype.ToString(o.NilValue)
+				continue
+			}
+			record[j] = ztype.ToString(v)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteCSVFile write csv file
+func WriteCSVFile(path string, data ztype.Maps, opt ...func(*WriteOptions)) error {
+	b, err := WriteCSV(data, opt...)
+	if err != nil {
+		return err
+	}
+	return zfile.WriteFile(path, b)
+}
@@ -0,0 +1,104 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/zlsgo/office/xlsx"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestCSV(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data := ztype.Maps{
+		{"id": "1", "name": " 张三 ", "remark": "a,b"},
+		{"id": "2", "name": "李四", "remark": ""},
+		{"id": "3", "name": "王五", "remark": "line\nbreak"},
+	}
+	opt := func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "name"}
+	}
+
+	b, err := xlsx.WriteCSV(data, opt, func(wo *xlsx.WriteOptions) {
+		wo.BOM = true
+	})
+	tt.NoError(err)
+	tt.EqualTrue(bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}))
+	tt.EqualTrue(strings.HasPrefix(string(b[3:]), "id,name,remark\n"))
+
+	csvFile := "./testdata/test_csv.csv"
+	xlsxFile := "./testdata/test_csv.xlsx"
+	defer os.Remove(csvFile)
+	defer os.Remove(xlsxFile)
+	tt.NoError(xlsx.WriteCSVFile(csvFile, data, opt))
+	tt.NoError(xlsx.WriteFile(xlsxFile, data, opt))
+
+	readOpt := func(ro *xlsx.ReadOptions) {
+		ro.TrimSpace = true
+		ro.HeaderMaps = map[string]string{"name": "姓名"}
+		ro.Fields = []string{"id", "姓名", "remark"}
+	}
+	fromCSV, err := xlsx.ReadCSV(csvFile, readOpt)
+	tt.NoError(err)
+	fromXlsx, err := xlsx.Read(xlsxFile, readOpt)
+	tt.NoError(err)
+	tt.Equal(fromXlsx, fromCSV)
+	tt.Equal(3, len(fromCSV))
+	tt.Equal("张三", fromCSV[0].Get("姓名").String())
+	tt.Equal("a,b", fromCSV[0].Get("remark").String())
+	tt.Equal(nil, fromCSV[1]["remark"])
+	tt.Equal("line\nbreak", fromCSV[2].Get("remark").String())
+
+	_, err = xlsx.ReadCSV("./testdata/not_exist.csv")
+	tt.EqualTrue(err != nil)
+}
+
+func TestCSVDetect(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	tsv, err := xlsx.WriteCSV(ztype.Maps{{"a": "1,5", "b": "x"}}, func(wo *xlsx.WriteOptions) {
+		wo.Delimiter = '\t'
+	})
+	tt.NoError(err)
+	tt.Equal("a\tb\n1,5\tx\n", string(tsv))
+
+	data, err := xlsx.ReadCSVFrom(bytes.NewReader(tsv))
+	tt.NoError(err)
+	tt.Equal("1,5", data[0].Get("a").String())
+
+	data, err = xlsx.ReadCSVFrom(strings.NewReader("skip;me\nname;age\nTom;18\n\n;\nJack;20\n"), func(ro *xlsx.ReadOptions) {
+		ro.OffsetY = 1
+		ro.RemoveEmptyRow = true
+	})
+	tt.NoError(err)
+	tt.Equal(2, len(data))
+	tt.Equal(20, data[1].Get("age").Int())
+
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String("姓名,城市\n张三,北京\n")
+	tt.NoError(err)
+	data, err = xlsx.ReadCSVFrom(strings.NewReader(gbk))
+	tt.NoError(err)
+	tt.Equal("北京", data[0].Get("城市").String())
+
+	data, err = xlsx.ReadCSVFrom(strings.NewReader("\xEF\xBB\xBFname|age\nTom|18\n"), func(ro *xlsx.ReadOptions) {
+		ro.NoHeaderRow = true
+		ro.Handler = func(row int, data ztype.Map) ztype.Map {
+			data["row"] = row
+			return data
+		}
+	})
+	tt.NoError(err)
+	tt.Equal(2, len(data))
+	tt.Equal("name", data[0].Get("A").String())
+	tt.Equal(1, data[1].Get("row").Int())
+
+	_, err = xlsx.ReadCSVFrom(strings.NewReader("name\n"))
+	tt.Equal("no data", err.Error())
+	_, err = xlsx.WriteCSV(ztype.Maps{})
+	tt.EqualTrue(err != nil)
+}
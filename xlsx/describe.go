@@ -0,0 +1,199 @@
+package xlsx
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sohaha/zlsgo/zarray"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+type (
+	// WorkbookInfo is the inspection report produced by Describe
+	WorkbookInfo struct {
+		Sheets []SheetDescription `json:"sheets"`
+	}
+
+	SheetDescription struct {
+		SheetInfo
+		Columns   []ColumnInfo `json:"columns"`
+		HeaderRow int          `json:"header_row"`
+		Rows      int          `json:"rows"`
+	}
+
+	ColumnInfo struct {
+		Key       string   `json:"key"`
+		Header    string   `json:"header"`
+		Column    string   `json:"column"`
+		Type      string   `json:"type"`
+		Samples   []string `json:"samples"`
+		NullRatio float64  `json:"null_ratio"`
+		Distinct  int      `json:"distinct"`
+	}
+)
+
+// Column types reported by Describe
+const (
+	ColumnTypeEmpty    = "empty"
+	ColumnTypeString   = "string"
+	ColumnTypeInt      = "int"
+	ColumnTypeFloat    = "float"
+	ColumnTypeBool     = "bool"
+	ColumnTypeDate     = "date"
+	ColumnTypeDateTime = "datetime"
+)
+
+const (
+	describeSampleRows   = 1000
+	describeSampleValues = 5
+)
+
+// Describe inspects the workbook and reports the used range, the detected
+// header and the inferred type of each column for every sheet, or only for
+// ReadOptions.Sheet when set. At most MaxRows (default 1000) data rows are sampled.
+func (x *Xlsx) Describe(opt ...func(*ReadOptions)) (*WorkbookInfo, error) {
+	o := zutil.Optional(ReadOptions{}, opt...)
+
+	info := &WorkbookInfo{Sheets: []SheetDescription{}}
+	for _, sheet := range x.Sheets() {
+		if o.Sheet != "" && o.Sheet != sheet.Name {
+			continue
+		}
+
+		desc, err := x.describeSheet(sheet, o)
+		if err != nil {
+			return nil, err
+		}
+		info.Sheets = append(info.Sheets, desc)
+	}
+
+	if o.Sheet != "" && len(info.Sheets) == 0 {
+		return nil, errors.New("sheet does not exist: " + o.Sheet)
+	}
+	return info, nil
+}
+
+func (x *Xlsx) describeSheet(sheet SheetInfo, o ReadOptions) (SheetDescription, error) {
+	desc := SheetDescription{SheetInfo: sheet, Columns: []ColumnInfo{}}
+
+	rows, err := x.f.GetRows(sheet.Name, o.Options)
+	if err != nil {
+		return desc, err
+	}
+	if len(rows) <= o.OffsetY {
+		return desc, nil
+	}
+
+	headers := append([]string{}, rows[o.OffsetY]...)
+	cols, _, rows := parseHeader(rows, o)
+	if !o.NoHeaderRow {
+		desc.HeaderRow = o.OffsetY + 1
+	}
+	dataStartRowNum := o.OffsetY + 1
+	if !o.NoHeaderRow {
+		dataStartRowNum = o.OffsetY + 2
+	}
+
+	limit := o.MaxRows
+	if limit <= 0 {
+		limit = describeSampleRows
+	}
+	if len(rows) < limit {
+		limit = len(rows)
+	}
+	desc.Rows = len(rows)
+
+	width := len(cols)
+	if o.NoHeaderRow {
+		for _, row := range rows[:limit] {
+			if len(row)-o.OffsetX > width {
+				width = len(row) - o.OffsetX
+			}
+		}
+	}
+
+	for j := 0; j < width; j++ {
+		col := ToCol(o.OffsetX + j)
+		column := ColumnInfo{Key: col, Column: col, Samples: []string{}}
+		if j < len(cols) {
+			column.Key = cols[j]
+		}
+		if !o.NoHeaderRow && o.OffsetX+j < len(headers) {
+			column.Header = headers[o.OffsetX+j]
+		}
+		if len(o.Fields) > 0 && !zarray.Contains(o.Fields, column.Key) {
+			continue
+		}
+
+		var (
+			nulls    int
+			types    = make(map[string]int)
+			distinct = make(map[string]struct{})
+		)
+		for i, row := range rows[:limit] {
+			text := ""
+			if o.OffsetX+j < len(row) {
+				text = row[o.OffsetX+j]
+			}
+			value := x.cellValue(sheet.Name, ToCell(dataStartRowNum+i-1, o.OffsetX+j))
+			if value == nil || text == "" {
+				nulls++
+				continue
+			}
+
+			types[valueType(value)]++
+			if _, ok := distinct[text]; !ok {
+				distinct[text] = struct{}{}
+				if len(column.Samples) < describeSampleValues {
+					column.Samples = append(column.Samples, text)
+				}
+			}
+		}
+
+		column.Type = mergeValueTypes(types)
+		column.Distinct = len(distinct)
+		if limit > 0 {
+			column.NullRatio = float64(nulls) / float64(limit)
+		}
+		desc.Columns = append(desc.Columns, column)
+	}
+
+	return desc, nil
+}
+
+func valueType(v interface{}) string {
+	switch val := v.(type) {
+	case bool:
+		return ColumnTypeBool
+	case int64:
+		return ColumnTypeInt
+	case float64:
+		return ColumnTypeFloat
+	case time.Time:
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
+			return ColumnTypeDate
+		}
+		return ColumnTypeDateTime
+	default:
+		return ColumnTypeString
+	}
+}
+
+func mergeValueTypes(types map[string]int) string {
+	switch len(types) {
+	case 0:
+		return ColumnTypeEmpty
+	case 1:
+		for t := range types {
+			return t
+		}
+	case 2:
+		if types[ColumnTypeInt] > 0 && types[ColumnTypeFloat] > 0 {
+			return ColumnTypeFloat
+		}
+		if types[ColumnTypeDate] > 0 && types[ColumnTypeDateTime] > 0 {
+			return ColumnTypeDateTime
+		}
+	}
+	return ColumnTypeString
+}
@@ -0,0 +1,78 @@
+package xlsx_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/zjson"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestDescribe(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	f, err := xlsx.Open("")
+	tt.NoError(err)
+	defer f.Close()
+
+	data := ztype.Maps{
+		{"id": 1, "name": "张三", "score": 90.5, "birthday": time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC), "vip": true, "remark": ""},
+		{"id": 2, "name": "李四", "score": 80, "birthday": time.Date(1991, 3, 4, 0, 0, 0, 0, time.UTC), "vip": false, "remark": ""},
+		{"id": 3, "name": "张三", "score": 70, "birthday": nil, "vip": true, "remark": "x"},
+	}
+	_, err = f.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.Sheet = "Users"
+		wo.First = []string{"id", "name", "score", "birthday", "vip"}
+	})
+	tt.NoError(err)
+
+	info, err := f.Describe()
+	tt.NoError(err)
+	tt.Equal(2, len(info.Sheets))
+	tt.Equal(0, len(info.Sheets[0].Columns))
+
+	users := info.Sheets[1]
+	tt.Equal("Users", users.Name)
+	tt.Equal("A1:F4", users.Dimension)
+	tt.Equal(1, users.HeaderRow)
+	tt.Equal(3, users.Rows)
+	tt.Equal(6, len(users.Columns))
+
+	columns := make(map[string]xlsx.ColumnInfo, len(users.Columns))
+	for _, c := range users.Columns {
+		columns[c.Key] = c
+	}
+	tt.Equal("A", columns["id"].Column)
+	tt.Equal(xlsx.ColumnTypeInt, columns["id"].Type)
+	tt.Equal(xlsx.ColumnTypeString, columns["name"].Type)
+	tt.Equal(2, columns["name"].Distinct)
+	tt.Equal([]string{"张三", "李四"}, columns["name"].Samples)
+	tt.Equal(xlsx.ColumnTypeFloat, columns["score"].Type)
+	tt.Equal(xlsx.ColumnTypeDate, columns["birthday"].Type)
+	tt.Equal(xlsx.ColumnTypeBool, columns["vip"].Type)
+	tt.EqualTrue(columns["birthday"].NullRatio > 0.3 && columns["birthday"].NullRatio < 0.4)
+
+	info, err = f.Describe(func(ro *xlsx.ReadOptions) {
+		ro.Sheet = "Users"
+		ro.Fields = []string{"姓名"}
+		ro.HeaderMaps = map[string]string{"name": "姓名"}
+	})
+	tt.NoError(err)
+	tt.Equal(1, len(info.Sheets))
+	tt.Equal(1, len(info.Sheets[0].Columns))
+	tt.Equal("name", info.Sheets[0].Columns[0].Header)
+	tt.Equal("姓名", info.Sheets[0].Columns[0].Key)
+
+	b, err := json.Marshal(info)
+	tt.NoError(err)
+	tt.Log(string(b))
+	tt.Equal("Users", zjson.GetBytes(b, "sheets.0.name").String())
+
+	_, err = f.Describe(func(ro *xlsx.ReadOptions) {
+		ro.Sheet = "NotExist"
+	})
+	tt.EqualTrue(err != nil)
+}
@@ -0,0 +1,333 @@
+package xlsx
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sohaha/zlsgo/zarray"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// Encoder writes rows to an output format, values are passed in header order
+type Encoder interface {
+	WriteHeader(header []string) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// encoderFactory builds the encoder of a format, options lists the
+// WriteOptions fields it applies besides the ones handled by Encode
+type encoderFactory struct {
+	fn      func(w io.Writer, o WriteOptions) Encoder
+	options []string
+}
+
+// encodeOptions are the WriteOptions fields applied by Encode for every format
+var encodeOptions = []string{"First", "Last", "NilValue"}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]encoderFactory{
+		FormatCSV: {
+			fn:      func(w io.Writer, o WriteOptions) Encoder { return newCSVEncoder(w, ',', o) },
+			options: []string{"BOM", "Delimiter"},
+		},
+		FormatTSV: {
+			fn:      func(w io.Writer, o WriteOptions) Encoder { return newCSVEncoder(w, '\t', o) },
+			options: []string{"BOM"},
+		},
+		FormatJSON: {fn: func(w io.Writer, _ WriteOptions) Encoder {
+			return &jsonEncoder{w: bufio.NewWriter(w)}
+		}},
+		FormatNDJSON: {fn: func(w io.Writer, _ WriteOptions) Encoder {
+			return &jsonEncoder{w: bufio.NewWriter(w), lines: true}
+		}},
+		FormatMarkdown: {fn: func(w io.Writer, _ WriteOptions) Encoder {
+			return &markdownEncoder{w: bufio.NewWriter(w)}
+		}},
+		FormatHTML: {fn: func(w io.Writer, _ WriteOptions) Encoder {
+			return &htmlEncoder{w: bufio.NewWriter(w)}
+		}},
+	}
+)
+
+// RegisterEncoder registers or replaces the encoder of a format, registered
+// encoders only get the options applied by Encode (First, Last and NilValue)
+func RegisterEncoder(format string, fn func(w io.Writer) Encoder) {
+	encodersMu.Lock()
+	encoders[strings.ToLower(format)] = encoderFactory{fn: func(w io.Writer, _ WriteOptions) Encoder {
+		return fn(w)
+	}}
+	encodersMu.Unlock()
+}
+
+// NewEncoder returns the encoder registered for the format
+func NewEncoder(format string, w io.Writer) (Encoder, bool) {
+	enc, err := newEncoder(format, w, WriteOptions{})
+	return enc, err == nil
+}
+
+// newEncoder returns the encoder of the format, options that the format
+// cannot apply are rejected rather than ignored
+func newEncoder(format string, w io.Writer, o WriteOptions) (Encoder, error) {
+	encodersMu.RLock()
+	e, ok := encoders[strings.ToLower(format)]
+	encodersMu.RUnlock()
+	if !ok {
+		return nil, errUnsupportedFormat(format)
+	}
+
+	if err := checkWriteOptions(format, o, encodeOptions, e.options); err != nil {
+		return nil, err
+	}
+	return e.fn(w, o), nil
+}
+
+// checkWriteOptions rejects the options set in o that are not supported by
+// the output rather than ignoring them
+func checkWriteOptions(output string, o WriteOptions, supported ...[]string) error {
+	v := reflect.ValueOf(o)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if v.Field(i).IsZero() || slices.ContainsFunc(supported, func(names []string) bool {
+			return slices.Contains(names, name)
+		}) {
+			continue
+		}
+		return errors.New(output + " output does not support WriteOptions." + name)
+	}
+	return nil
+}
+
+// Encode writes the rows to w in the given format, keeping the header order
+// unless WriteOptions.First/Last move columns, options that the format
+// cannot apply are rejected
+func Encode(w io.Writer, format string, header []string, data ztype.Maps, opt ...func(*WriteOptions)) error {
+	o := zutil.Optional(WriteOptions{}, opt...)
+	enc, err := newEncoder(format, w, o)
+	if err != nil {
+		return err
+	}
+
+	header = zarray.SortWithPriority(header, o.First, o.Last)
+	if err = enc.WriteHeader(header); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(header))
+	for i := range data {
+		if err = encodeRow(enc, header, data[i], values, o); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// encodeRow writes the values of the row in header order into values and
+// then to the encoder, nil values are replaced by NilValue
+func encodeRow(enc Encoder, header []string, row ztype.Map, values []interface{}, o WriteOptions) error {
+	for j := range header {
+		values[j] = row[header[j]]
+		if values[j] == nil {
+			values[j] = o.NilValue
+		}
+	}
+	return enc.WriteRow(values)
+}
+
+func encodeText(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return val.Format(time.DateTime)
+	default:
+		return ztype.ToString(v)
+	}
+}
+
+type csvEncoder struct {
+	w   *csv.Writer
+	out io.Writer
+	bom bool
+}
+
+func newCSVEncoder(w io.Writer, comma rune, o WriteOptions) *csvEncoder {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if o.Delimiter != 0 {
+		cw.Comma = o.Delimiter
+	}
+	return &csvEncoder{w: cw, out: w, bom: o.BOM}
+}
+
+func (e *csvEncoder) WriteHeader(header []string) error {
+	if e.bom {
+		if _, err := e.out.Write(utf8BOM); err != nil {
+			return err
+		}
+	}
+	return e.w.Write(header)
+}
+
+func (e *csvEncoder) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i := range values {
+		record[i] = encodeText(values[i])
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonEncoder struct {
+	w      *bufio.Writer
+	header [][]byte
+	rows   int
+	lines  bool
+}
+
+func (e *jsonEncoder) WriteHeader(header []string) error {
+	e.header = make([][]byte, len(header))
+	for i := range header {
+		b, err := json.Marshal(header[i])
+		if err != nil {
+			return err
+		}
+		e.header[i] = b
+	}
+	if !e.lines {
+		_, err := e.w.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+func (e *jsonEncoder) WriteRow(values []interface{}) error {
+	if !e.lines {
+		if e.rows > 0 {
+			_ = e.w.WriteByte(',')
+		}
+		_ = e.w.WriteByte('\n')
+	}
+	e.rows++
+
+	_ = e.w.WriteByte('{')
+	for i := range values {
+		if i > 0 {
+			_ = e.w.WriteByte(',')
+		}
+		b, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		_, _ = e.w.Write(e.header[i])
+		_ = e.w.WriteByte(':')
+		_, _ = e.w.Write(b)
+	}
+	_ = e.w.WriteByte('}')
+
+	if e.lines {
+		return e.w.WriteByte('\n')
+	}
+	return nil
+}
+
+func (e *jsonEncoder) Close() error {
+	if !e.lines {
+		if e.rows > 0 {
+			_ = e.w.WriteByte('\n')
+		}
+		_, _ = e.w.WriteString("]\n")
+	}
+	return e.w.Flush()
+}
+
+type markdownEncoder struct {
+	w *bufio.Writer
+}
+
+var markdownReplacer = strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>")
+
+func (e *markdownEncoder) writeLine(cells []string) error {
+	_, _ = e.w.WriteString("|")
+	for i := range cells {
+		_, _ = e.w.WriteString(" " + markdownReplacer.Replace(cells[i]) + " |")
+	}
+	_, err := e.w.WriteString("\n")
+	return err
+}
+
+func (e *markdownEncoder) WriteHeader(header []string) error {
+	if err := e.writeLine(header); err != nil {
+		return err
+	}
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	_, err := e.w.WriteString("|" + strings.Join(sep, "|") + "|\n")
+	return err
+}
+
+func (e *markdownEncoder) WriteRow(values []interface{}) error {
+	cells := make([]string, len(values))
+	for i := range values {
+		cells[i] = encodeText(values[i])
+	}
+	return e.writeLine(cells)
+}
+
+func (e *markdownEncoder) Close() error {
+	return e.w.Flush()
+}
+
+type htmlEncoder struct {
+	w *bufio.Writer
+}
+
+const htmlTableStyle = `<style>
+table.office-table{border-collapse:collapse;font-family:-apple-system,"Segoe UI","PingFang SC","Microsoft YaHei",sans-serif;font-size:14px}
+table.office-table th,table.office-table td{border:1px solid #d0d7de;padding:6px 12px;text-align:left;vertical-align:top}
+table.office-table th{background:#f3f4f6;font-weight:600}
+table.office-table tr:nth-child(even) td{background:#fafafa}
+</style>
+`
+
+func (e *htmlEncoder) WriteHeader(header []string) error {
+	_, _ = e.w.WriteString(htmlTableStyle)
+	_, _ = e.w.WriteString("<table class=\"office-table\">\n<thead>\n<tr>")
+	for i := range header {
+		_, _ = e.w.WriteString("<th>" + html.EscapeString(header[i]) + "</th>")
+	}
+	_, err := e.w.WriteString("</tr>\n</thead>\n<tbody>\n")
+	return err
+}
+
+func (e *htmlEncoder) WriteRow(values []interface{}) error {
+	_, _ = e.w.WriteString("<tr>")
+	for i := range values {
+		text := strings.ReplaceAll(html.EscapeString(encodeText(values[i])), "\n", "<br>")
+		_, _ = e.w.WriteString("<td>" + text + "</td>")
+	}
+	_, err := e.w.WriteString("</tr>\n")
+	return err
+}
+
+func (e *htmlEncoder) Close() error {
+	_, _ = e.w.WriteString("</tbody>\n</table>\n")
+	return e.w.Flush()
+}
@@ -2,6 +2,7 @@ package xlsx
 
 import (
 	"errors"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,6 +22,24 @@ type Xlsx struct {
 func Open(path string) (*Xlsx, error) {
 	if path != "" {
 		path = zfile.RealPath(path)
+		if isOLEFile(path) {
+			f, err := openXLS(path)
+			if err == nil {
+				// legacy workbooks are converted in memory and cannot be saved back in place
+				return &Xlsx{f: f}, nil
+			}
+			if err != errNotXLS {
+				return nil, err
+			}
+		}
+		if isODSFile(path) {
+			f, err := openODS(path)
+			if err != nil {
+				return nil, err
+			}
+			return &Xlsx{f: f, path: path}, nil
+		}
+
 		f, err := excelize.OpenFile(path)
 		if err != nil {
 			if !strings.Contains(err.Error(), "no such file") {
@@ -43,11 +62,16 @@ func (x *Xlsx) Engine() *excelize.File {
 }
 
 func (x *Xlsx) Read(opt ...func(*ReadOptions)) (ztype.Maps, error) {
-	o := zutil.Optional(ReadOptions{}, opt...)
+	_, data, err := x.read(zutil.Optional(ReadOptions{}, opt...))
+	return data, err
+}
+
+// read returns the column keys in sheet order along with the rows
+func (x *Xlsx) read(o ReadOptions) ([]string, ztype.Maps, error) {
 	if o.Sheet == "" {
 		sheets := x.f.GetSheetList()
 		if len(sheets) == 0 {
-			return nil, errors.New("no sheet")
+			return nil, nil, errors.New("no sheet")
 		}
 		o.Sheet = sheets[0]
 	}
@@ -59,13 +83,24 @@ func (x *Xlsx) Read(opt ...func(*ReadOptions)) (ztype.Maps, error) {
 		rawOpt.RawCellValue = true
 		rawRows, err = x.f.GetRows(o.Sheet, rawOpt)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	rows, err := x.f.GetRows(o.Sheet, o.Options)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	rows, rawRows = clipRows(rows, o), clipRows(rawRows, o)
+
+	headerRows := 1
+	if o.HeaderRows > 1 && !o.NoHeaderRow {
+		merges, err := x.f.GetMergeCells(o.Sheet)
+		if err != nil {
+			return nil, nil, err
+		}
+		headerRows = o.HeaderRows
+		rows, rawRows = mergeHeaderRows(rows, merges, o), mergeHeaderRows(rawRows, nil, o)
 	}
 
 	minRows := o.OffsetY + 2
@@ -73,27 +108,10 @@ func (x *Xlsx) Read(opt ...func(*ReadOptions)) (ztype.Maps, error) {
 		minRows = o.OffsetY + 1
 	}
 	if len(rows) < minRows {
-		return ztype.Maps{}, errors.New("no data")
+		return nil, ztype.Maps{}, errors.New("no data")
 	}
 
-	var (
-		cols      []string
-		headerRow = rows[o.OffsetY]
-	)
-
-	colsIndex := make([]string, len(headerRow))
-	for i := range headerRow {
-		colsIndex[i] = ToCol(i)
-	}
-
-	if o.NoHeaderRow {
-		cols = make([]string, len(headerRow))
-		copy(cols, colsIndex)
-		rows = rows[o.OffsetY:]
-	} else {
-		cols = rows[o.OffsetY]
-		rows = rows[o.OffsetY+1:]
-	}
+	cols, _, rows := parseHeader(rows, o)
 
 	rawStart := o.OffsetY
 	if !o.NoHeaderRow {
@@ -105,48 +123,9 @@ func (x *Xlsx) Read(opt ...func(*ReadOptions)) (ztype.Maps, error) {
 		rawRows = [][]string{}
 	}
 
-	if o.OffsetX > 0 {
-		if o.OffsetX < len(cols) {
-			cols = cols[o.OffsetX:]
-		} else {
-			cols = []string{}
-		}
-	}
-
-	if o.TrimSpace {
-		for i := range cols {
-			cols[i] = strings.TrimSpace(cols[i])
-		}
-		for i := range cols {
-			if cols[i] == "" {
-				cols[i] = colsIndex[o.OffsetX+i]
-			}
-		}
-	}
-
-	if o.HeaderHandler != nil {
-		for i := range cols {
-			cols[i] = o.HeaderHandler(colsIndex[o.OffsetX+i], cols[i])
-		}
-	}
-
-	if len(o.HeaderMaps) > 0 {
-		for i := range cols {
-			if mapped, ok := o.HeaderMaps[cols[i]]; ok {
-				cols[i] = mapped
-			}
-		}
-	}
-
 	dataStartRowNum := o.OffsetY + 1
 	if !o.NoHeaderRow {
-		dataStartRowNum = o.OffsetY + 2
-	}
-
-	type rowMeta struct {
-		row    []string
-		rawRow []string
-		rowNum int
+		dataStartRowNum = o.OffsetY + 1 + headerRows
 	}
 
 	rowsMeta := make([]rowMeta, len(rows))
@@ -158,7 +137,78 @@ func (x *Xlsx) Read(opt ...func(*ReadOptions)) (ztype.Maps, error) {
 		}
 		rowsMeta[i] = rowMeta{row: row, rawRow: rawRow, rowNum: rowNum}
 	}
+	if err = readImages(x.f, o.Sheet, rowsMeta, cols, o); err != nil {
+		return nil, nil, err
+	}
+	if err = readLinksAndComments(x.f, o.Sheet, rowsMeta, cols, o); err != nil {
+		return nil, nil, err
+	}
 
+	var formulaMu sync.Mutex
+
+	data := mapRows(rowsMeta, cols, o, func(meta rowMeta, j int, key string) string {
+		value := meta.row[o.OffsetX+j]
+		needRawValue := (o.RawCellValue || zarray.Contains(o.RawCellValueFields, key)) && !zarray.Contains(o.CalcCellValueFields, key)
+		if needRawValue {
+			if o.OffsetX+j < len(meta.rawRow) && meta.rawRow[o.OffsetX+j] != "" {
+				value = meta.rawRow[o.OffsetX+j]
+			}
+			if value == "" {
+				cellAddr := ToCol(o.OffsetX+j) + strconv.Itoa(meta.rowNum)
+				formulaMu.Lock()
+				formula, err := x.f.GetCellFormula(o.Sheet, cellAddr)
+				formulaMu.Unlock()
+				if err == nil && formula != "" {
+					value = formula
+				}
+			}
+		} else if value == "" || strings.HasPrefix(value, "=") {
+			cellAddr := ToCol(o.OffsetX+j) + strconv.Itoa(meta.rowNum)
+			formulaMu.Lock()
+			calcVal, err := x.f.CalcCellValue(o.Sheet, cellAddr)
+			formulaMu.Unlock()
+			if err == nil && calcVal != "" {
+				value = calcVal
+			}
+		}
+		return value
+	})
+	return columnKeys(cols, data, o), data, nil
+}
+
+// clipRows drops the rows and columns beyond the bounds of the read
+func clipRows(rows [][]string, o ReadOptions) [][]string {
+	if o.lastRow > 0 && len(rows) > o.lastRow {
+		rows = rows[:o.lastRow]
+	}
+	if o.lastCol > 0 {
+		for i := range rows {
+			if len(rows[i]) > o.lastCol {
+				rows[i] = rows[i][:o.lastCol]
+			}
+		}
+	}
+	return rows
+}
+
+type rowMeta struct {
+	// extra holds the values read besides the cell text, such as images
+	extra  map[string]interface{}
+	row    []string
+	rawRow []string
+	rowNum int
+}
+
+func (m *rowMeta) setExtra(key string, v interface{}) {
+	if m.extra == nil {
+		m.extra = make(map[string]interface{})
+	}
+	m.extra[key] = v
+}
+
+// mapRows converts data rows into maps keyed by cols following ReadOptions,
+// value resolves the final value of the j-th column after OffsetX
+func mapRows(rowsMeta []rowMeta, cols []string, o ReadOptions, value func(meta rowMeta, j int, key string) string) ztype.Maps {
 	if o.Reverse {
 		rowsMeta = zarray.Reverse(rowsMeta)
 	}
@@ -180,142 +230,180 @@ func (x *Xlsx) Read(opt ...func(*ReadOptions)) (ztype.Maps, error) {
 		}
 	}
 
-	var formulaMu sync.Mutex
-
 	result := zarray.Map(rowsMeta, func(index int, meta rowMeta) ztype.Map {
-		row := meta.row
-		data := make(ztype.Map, len(row))
-
-		isEmptyRow := true
-		rowEffective := row
-		rawRowEffective := []string{}
-		if o.OffsetX < len(meta.rawRow) {
-			rawRowEffective = meta.rawRow[o.OffsetX:]
+		data, empty := mapRow(meta, cols, o, value)
+		if empty {
+			return ztype.Map{}
+		}
+		if o.Handler != nil {
+			return o.Handler(index, data)
 		}
-		rowNum := meta.rowNum
 
-		if o.OffsetX > 0 {
-			if o.OffsetX < len(row) {
-				rowEffective = row[o.OffsetX:]
-			} else {
-				rowEffective = []string{}
-			}
+		return data
+	}, parallel)
+
+	if o.RemoveEmptyRow {
+		result = zarray.Filter(result, func(_ int, v ztype.Map) bool {
+			return len(v) > 0
+		})
+	}
+
+	return result
+}
+
+// mapRow converts a data row into a map keyed by cols, reporting rows
+// without any value as empty
+func mapRow(meta rowMeta, cols []string, o ReadOptions, value func(meta rowMeta, j int, key string) string) (ztype.Map, bool) {
+	row := meta.row
+	data := make(ztype.Map, len(row))
+
+	isEmptyRow := true
+	rowEffective := row
+	if o.OffsetX > 0 {
+		if o.OffsetX < len(row) {
+			rowEffective = row[o.OffsetX:]
+		} else {
+			rowEffective = []string{}
+		}
+	}
+
+	for j := range rowEffective {
+		key := ""
+		if j < len(cols) {
+			key = cols[j]
+		} else if o.NoHeaderRow {
+			key = ToCol(o.OffsetX + j)
+		} else {
+			continue
 		}
 
-		for j := range rowEffective {
-			if j >= len(cols) {
-				if o.NoHeaderRow {
-					key := ToCol(o.OffsetX + j)
-					if len(o.Fields) > 0 && !zarray.Contains(o.Fields, key) {
-						continue
-					}
-
-					value := rowEffective[j]
-					needRawValue := (o.RawCellValue || zarray.Contains(o.RawCellValueFields, key)) && !zarray.Contains(o.CalcCellValueFields, key)
-					if needRawValue {
-						if len(rawRowEffective) > j && rawRowEffective[j] != "" {
-							value = rawRowEffective[j]
-						}
-						if value == "" {
-							cellAddr := ToCol(o.OffsetX+j) + strconv.Itoa(rowNum)
-							formulaMu.Lock()
-							formula, err := x.f.GetCellFormula(o.Sheet, cellAddr)
-							formulaMu.Unlock()
-							if err == nil && formula != "" {
-								value = formula
-							}
-						}
-					} else if value == "" || strings.HasPrefix(value, "=") {
-						cellAddr := ToCol(o.OffsetX+j) + strconv.Itoa(rowNum)
-						formulaMu.Lock()
-						calcVal, err := x.f.CalcCellValue(o.Sheet, cellAddr)
-						formulaMu.Unlock()
-						if err == nil && calcVal != "" {
-							value = calcVal
-						}
-					}
-					if o.TrimSpace {
-						value = strings.TrimSpace(value)
-					}
-
-					data[key] = value
-					if isEmptyRow && rowEffective[j] != "" {
-						isEmptyRow = false
-					}
-				}
-				continue
-			}
+		if len(o.Fields) > 0 && !zarray.Contains(o.Fields, key) {
+			continue
+		}
 
-			if len(o.Fields) > 0 && !zarray.Contains(o.Fields, cols[j]) {
-				continue
-			}
+		v := value(meta, j, key)
+		if o.TrimSpace {
+			v = strings.TrimSpace(v)
+		}
 
-			value := rowEffective[j]
-			needRawValue := (o.RawCellValue || zarray.Contains(o.RawCellValueFields, cols[j])) && !zarray.Contains(o.CalcCellValueFields, cols[j])
-			if needRawValue {
-				if len(rawRowEffective) > j && rawRowEffective[j] != "" {
-					value = rawRowEffective[j]
-				}
-				if value == "" {
-					cellAddr := ToCol(o.OffsetX+j) + strconv.Itoa(rowNum)
-					formulaMu.Lock()
-					formula, err := x.f.GetCellFormula(o.Sheet, cellAddr)
-					formulaMu.Unlock()
-					if err == nil && formula != "" {
-						value = formula
-					}
-				}
-			} else if value == "" || strings.HasPrefix(value, "=") {
-				cellAddr := ToCol(o.OffsetX+j) + strconv.Itoa(rowNum)
-				formulaMu.Lock()
-				calcVal, err := x.f.CalcCellValue(o.Sheet, cellAddr)
-				formulaMu.Unlock()
-				if err == nil && calcVal != "" {
-					value = calcVal
+		data[key] = v
+		if isEmptyRow && rowEffective[j] != "" {
+			isEmptyRow = false
+		}
+	}
+
+	for k, v := range meta.extra {
+		data[k] = v
+		isEmptyRow = false
+	}
+
+	if isEmptyRow {
+		return nil, true
+	}
+	if !o.NoHeaderRow {
+		if len(o.Fields) > 0 {
+			for _, k := range o.Fields {
+				if _, ok := data[k]; !ok {
+					data[k] = nil
 				}
 			}
-			if o.TrimSpace {
-				value = strings.TrimSpace(value)
+		} else {
+			for _, k := range cols {
+				if _, ok := data[k]; !ok {
+					data[k] = nil
+				}
 			}
+		}
+	}
+	return data, false
+}
 
-			data[cols[j]] = value
-			if isEmptyRow && rowEffective[j] != "" {
-				isEmptyRow = false
+// columnKeys returns the keys of the rows in column order, keys that are not
+// columns of the header (added by Handler or beyond it) are appended sorted
+func columnKeys(cols []string, data ztype.Maps, o ReadOptions) []string {
+	base := cols
+	if len(o.Fields) > 0 {
+		base = o.Fields
+	}
+
+	keys := make([]string, 0, len(base))
+	seen := make(map[string]struct{}, len(base))
+	for _, k := range base {
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+
+	extra := []string{}
+	for i := range data {
+		for k := range data[i] {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				extra = append(extra, k)
 			}
 		}
+	}
+	sort.Slice(extra, func(i, j int) bool {
+		if len(extra[i]) != len(extra[j]) {
+			return len(extra[i]) < len(extra[j])
+		}
+		return extra[i] < extra[j]
+	})
 
-		if isEmptyRow {
-			return ztype.Map{}
+	return append(keys, extra...)
+}
+
+// parseHeader resolves the column keys from the header row (or column letters
+// when NoHeaderRow is set) and returns them with the column letters and the data rows
+func parseHeader(rows [][]string, o ReadOptions) (cols []string, colsIndex []string, data [][]string) {
+	headerRow := rows[o.OffsetY]
+	colsIndex = make([]string, len(headerRow))
+	for i := range headerRow {
+		colsIndex[i] = ToCol(i)
+	}
+
+	if o.NoHeaderRow {
+		cols = make([]string, len(headerRow))
+		copy(cols, colsIndex)
+		data = rows[o.OffsetY:]
+	} else {
+		cols = rows[o.OffsetY]
+		data = rows[o.OffsetY+1:]
+	}
+
+	if o.OffsetX > 0 {
+		if o.OffsetX < len(cols) {
+			cols = cols[o.OffsetX:]
+		} else {
+			cols = []string{}
 		}
-		if !o.NoHeaderRow {
-			if len(o.Fields) > 0 {
-				for _, k := range o.Fields {
-					if _, ok := data[k]; !ok {
-						data[k] = nil
-					}
-				}
-			} else {
-				for _, k := range cols {
-					if _, ok := data[k]; !ok {
-						data[k] = nil
-					}
-				}
+	}
+
+	if o.TrimSpace {
+		for i := range cols {
+			cols[i] = strings.TrimSpace(cols[i])
+		}
+		for i := range cols {
+			if cols[i] == "" {
+				cols[i] = colsIndex[o.OffsetX+i]
 			}
 		}
+	}
 
-		if o.Handler != nil {
-			return o.Handler(index, data)
+	if o.HeaderHandler != nil {
+		for i := range cols {
+			cols[i] = o.HeaderHandler(colsIndex[o.OffsetX+i], cols[i])
 		}
+	}
 
-		return data
-	}, parallel)
-	rowsMeta = nil
-
-	if o.RemoveEmptyRow {
-		result = zarray.Filter(result, func(_ int, v ztype.Map) bool {
-			return len(v) > 0
-		})
+	if len(o.HeaderMaps) > 0 {
+		for i := range cols {
+			if mapped, ok := o.HeaderMaps[cols[i]]; ok {
+				cols[i] = mapped
+			}
+		}
 	}
 
-	return result, nil
+	return cols, colsIndex, data
 }
@@ -0,0 +1,88 @@
+package xlsx
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// formulaPattern matches the placeholders of formula templates
+var formulaPattern = regexp.MustCompile(`\{(row|first|last|col:[^}]+)\}`)
+
+// expandFormula replaces the placeholders of a formula template: {row} is
+// the row being written, {first} and {last} are the first and last data
+// rows, and {col:field} is the column letter of the field
+func expandFormula(tpl string, header []string, row, first, last int) (string, error) {
+	var err error
+	formula := formulaPattern.ReplaceAllStringFunc(strings.TrimPrefix(tpl, "="), func(m string) string {
+		switch name := m[1 : len(m)-1]; name {
+		case "row":
+			return strconv.Itoa(row)
+		case "first":
+			return strconv.Itoa(first)
+		case "last":
+			return strconv.Itoa(last)
+		default:
+			field := strings.TrimPrefix(name, "col:")
+			i := slices.Index(header, field)
+			if i < 0 {
+				err = errors.New("formula references unknown field: " + field)
+				return m
+			}
+			return ToCol(i)
+		}
+	})
+	return formula, err
+}
+
+// setRowFormulas writes the formulas of the fields in the given row
+func setRowFormulas(f *excelize.File, sheet string, header []string, formulas map[string]string, row, first, last int) error {
+	for i := range header {
+		tpl, ok := formulas[header[i]]
+		if !ok {
+			continue
+		}
+		formula, err := expandFormula(tpl, header, row, first, last)
+		if err != nil {
+			return err
+		}
+		if err = f.SetCellFormula(sheet, ToCol(i)+strconv.Itoa(row), formula); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addSummaryRow writes the summary formulas below the data rows, the label
+// goes in the first column when it has no formula
+func addSummaryRow(f *excelize.File, sheet string, header []string, first, last int, o WriteOptions) error {
+	if len(o.Summary) == 0 || len(header) == 0 {
+		return nil
+	}
+	if o.AsTable != nil && len(o.AsTable.Totals) > 0 {
+		return errors.New("summary row conflicts with the table totals row")
+	}
+
+	row := last + 1
+	if _, ok := o.Summary[header[0]]; !ok && o.SummaryLabel != "" {
+		if err := f.SetCellStr(sheet, "A"+strconv.Itoa(row), o.SummaryLabel); err != nil {
+			return err
+		}
+	}
+	return setRowFormulas(f, sheet, header, o.Summary, row, first, last)
+}
+
+// formulaFields returns the fields of Formulas in a stable order
+func formulaFields(o WriteOptions) []string {
+	fields := make([]string, 0, len(o.Formulas))
+	for field := range o.Formulas {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
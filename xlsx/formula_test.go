@@ -0,0 +1,67 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteFormulas(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data := ztype.Maps{
+		{"item": "a", "price": 2.5, "qty": 4, "note": "=1+1"},
+		{"item": "b", "price": 10, "qty": 3, "note": ""},
+	}
+	b, err := xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"item", "price", "qty", "total"}
+		wo.Formulas = map[string]string{"total": "={col:price}{row}*{col:qty}{row}"}
+		wo.Summary = map[string]string{
+			"qty":   "SUM({col:qty}{first}:{col:qty}{last})",
+			"total": "=SUM({col:total}2:{col:total}{last})",
+		}
+		wo.SummaryLabel = "合计"
+	})
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+
+	rows, err := f.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal([]string{"item", "price", "qty", "total", "note"}, rows[0])
+	tt.Equal("=1+1", rows[1][4])
+
+	formula, err := f.GetCellFormula("Sheet1", "D3")
+	tt.NoError(err)
+	tt.Equal("B3*C3", formula)
+	formula, _ = f.GetCellFormula("Sheet1", "D4")
+	tt.Equal("SUM(D2:D3)", formula)
+	formula, _ = f.GetCellFormula("Sheet1", "C4")
+	tt.Equal("SUM(C2:C3)", formula)
+	label, _ := f.GetCellValue("Sheet1", "A4")
+	tt.Equal("合计", label)
+
+	total, err := f.CalcCellValue("Sheet1", "D2")
+	tt.NoError(err)
+	tt.Equal("10", total)
+	total, err = f.CalcCellValue("Sheet1", "D4")
+	tt.NoError(err)
+	tt.Equal("40", total)
+
+	_, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.Formulas = map[string]string{"total": "={col:amount}{row}"}
+	})
+	tt.EqualTrue(err != nil)
+
+	_, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.Summary = map[string]string{"qty": "SUM(C2:C3)"}
+		wo.AsTable = &xlsx.TableOptions{Totals: map[string]string{"qty": "sum"}}
+	})
+	tt.EqualTrue(err != nil)
+}
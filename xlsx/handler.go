@@ -0,0 +1,191 @@
+package xlsx
+
+import (
+	"fmt"
+
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+)
+
+type (
+	// CellContext describes the cell passed to CellHandlerV2
+	CellContext struct {
+		Value interface{}
+		// Row is the data of the row, nil for header cells
+		Row   ztype.Map
+		Sheet string
+		Cell  string
+		Field string
+		// Index is the index of the data row, -1 for header cells
+		Index  int
+		Header bool
+	}
+	// CellResult changes the cell returned by CellHandlerV2, zero fields
+	// leave the cell as written
+	CellResult struct {
+		// Value replaces the value of the cell before it is written, it cannot
+		// be set for the cells of Formulas and ImageFields, nor can Link
+		Value interface{}
+		// Link writes the cell as a hyperlink, taking precedence over Value
+		Link *Link
+		// Comment replaces the comment returned by CommentHandler for the cell
+		Comment  *Comment
+		RichText []RichText
+		Style    int
+	}
+)
+
+// handleCell runs CellHandlerV2 on the cell, errors are reported with the cell
+func handleCell(ctx CellContext, o WriteOptions) (CellResult, error) {
+	res, err := o.CellHandlerV2(ctx)
+	if err != nil {
+		return res, fmt.Errorf("%s!%s: %w", ctx.Sheet, ctx.Cell, err)
+	}
+	return res, nil
+}
+
+// value returns the value to write in place of v
+func (r CellResult) value(v interface{}) interface{} {
+	switch {
+	case r.Link != nil:
+		return *r.Link
+	case r.Value != nil:
+		return r.Value
+	}
+	return v
+}
+
+// apply sets the style, rich text and comment of the written cell, col is
+// the index of its column
+func (r CellResult) apply(f *excelize.File, sheet, cell string, col int, header bool, styles *numFmtStyles, o WriteOptions) error {
+	if err := setHandlerStyle(f, sheet, cell, r.Style, col, header, styles, o); err != nil {
+		return err
+	}
+	if r.RichText != nil {
+		if err := setRichText(f, sheet, cell, r.RichText); err != nil {
+			return err
+		}
+	}
+	if r.Comment != nil && r.Comment.Text != "" {
+		return f.AddComment(sheet, excelize.Comment{Cell: cell, Author: r.Comment.Author, Text: r.Comment.Text})
+	}
+	return nil
+}
+
+// applyCellHandler runs CellHandler on the written cell
+func applyCellHandler(f *excelize.File, sheet, cell string, value interface{}, col int, header bool, styles *numFmtStyles, o WriteOptions) error {
+	richTextRuns, styleID := o.CellHandler(sheet, cell, value)
+	if err := setHandlerStyle(f, sheet, cell, styleID, col, header, styles, o); err != nil {
+		return err
+	}
+	if richTextRuns == nil {
+		return nil
+	}
+	return setRichText(f, sheet, cell, richTextRuns)
+}
+
+// setHandlerStyle sets the style returned by a cell handler, on protected
+// sheets a copy of it keeps the protection of the cell: header cells are
+// locked and data cells follow their column
+func setHandlerStyle(f *excelize.File, sheet, cell string, id, col int, header bool, styles *numFmtStyles, o WriteOptions) error {
+	if id <= 0 {
+		return nil
+	}
+	if o.Protect != nil {
+		var err error
+		if id, err = styles.protected(id, col, header); err != nil {
+			return err
+		}
+	}
+	return f.SetCellStyle(sheet, cell, cell, id)
+}
+
+// handlerStyle keys the protected copies of the styles returned by handlers
+type handlerStyle int
+
+func (s *numFmtStyles) protected(id, col int, header bool) (int, error) {
+	locked := header
+	if base := s.columns[col]; !header && base != nil && base.Protection != nil {
+		locked = base.Protection.Locked
+	}
+	key := [2]interface{}{handlerStyle(id), excelize.Protection{Locked: locked}}
+	if protected, ok := s.styles[key]; ok {
+		return protected, nil
+	}
+
+	style, err := s.f.GetStyle(id)
+	if err != nil {
+		return 0, err
+	}
+	protected, err := s.f.NewStyle(protectStyle(style, locked))
+	if err != nil {
+		return 0, err
+	}
+	s.styles[key] = protected
+	return protected, nil
+}
+
+func setRichText(f *excelize.File, sheet, cell string, runs []RichText) error {
+	excelizeRuns := make([]excelize.RichTextRun, len(runs))
+	for i, rt := range runs {
+		excelizeRuns[i] = excelize.RichTextRun(rt)
+	}
+	return f.SetCellRichText(sheet, cell, excelizeRuns)
+}
+
+// handleHeader runs the cell handlers on the header cells
+func handleHeader(f *excelize.File, sheet string, header []string, grid [][]string, styles *numFmtStyles, o WriteOptions) error {
+	if o.CellHandler == nil && o.CellHandlerV2 == nil {
+		return nil
+	}
+
+	for r := range grid {
+		for i := range grid[r] {
+			if grid[r][i] == "" {
+				continue
+			}
+			cell := ToCell(r, i)
+			if o.CellHandlerV2 != nil {
+				res, err := handleCell(CellContext{
+					Value:  grid[r][i],
+					Sheet:  sheet,
+					Cell:   cell,
+					Field:  header[i],
+					Index:  -1,
+					Header: true,
+				}, o)
+				if err != nil {
+					return err
+				}
+				if err = setHeaderResult(f, sheet, cell, i, res, styles, o); err != nil {
+					return err
+				}
+			}
+			if o.CellHandler != nil {
+				if err := applyCellHandler(f, sheet, cell, grid[r][i], i, true, styles, o); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// setHeaderResult applies the result of CellHandlerV2 to a header cell, header
+// links stay locked on protected sheets
+func setHeaderResult(f *excelize.File, sheet, cell string, col int, res CellResult, styles *numFmtStyles, o WriteOptions) error {
+	switch {
+	case res.Link != nil:
+		if err := f.SetCellValue(sheet, cell, res.Link.text()); err != nil {
+			return err
+		}
+		if err := setLink(f, sheet, cell, col, *res.Link, styles, o.Protect != nil); err != nil {
+			return err
+		}
+	case res.Value != nil:
+		if err := f.SetCellValue(sheet, cell, res.Value); err != nil {
+			return err
+		}
+	}
+	return res.apply(f, sheet, cell, col, true, styles, o)
+}
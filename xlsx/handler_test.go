@@ -0,0 +1,161 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteCellHandlerV2(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data := ztype.Maps{
+		{"id": 1, "status": "ok", "url": "https://example.com/1"},
+		{"id": 2, "status": "failed", "url": "https://example.com/2"},
+	}
+	x, err := xlsx.Open("")
+	tt.NoError(err)
+	defer x.Close()
+	red, err := x.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "#FF0000"}})
+	tt.NoError(err)
+
+	contexts := []xlsx.CellContext{}
+	b, err := x.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "status", "url"}
+		wo.CellHandlerV2 = func(ctx xlsx.CellContext) (xlsx.CellResult, error) {
+			contexts = append(contexts, ctx)
+			res := xlsx.CellResult{}
+			switch {
+			case ctx.Header && ctx.Field == "status":
+				res.Value = "状态"
+				res.Comment = &xlsx.Comment{Author: "bot", Text: "ok or failed"}
+			case ctx.Header:
+			case ctx.Field == "status" && ctx.Row.Get("status").String() == "failed":
+				res.Style = red
+				res.Value = "失败"
+			case ctx.Field == "url":
+				res.Link = &xlsx.Link{Text: "#" + ctx.Row.Get("id").String(), URL: ctx.Value.(string)}
+			case ctx.Field == "id" && ctx.Index == 0:
+				res.RichText = []xlsx.RichText{{Text: "1", Font: &excelize.Font{Bold: true}}}
+			}
+			return res, nil
+		}
+	})
+	tt.NoError(err)
+	tt.Equal(9, len(contexts))
+	tt.EqualTrue(contexts[0].Header)
+	tt.Equal(-1, contexts[0].Index)
+	tt.Equal("A1", contexts[0].Cell)
+	tt.Equal("status", contexts[4].Field)
+	tt.Equal("B2", contexts[4].Cell)
+	tt.Equal(0, contexts[4].Index)
+	tt.Equal("ok", contexts[4].Value)
+	tt.Equal(1, contexts[4].Row.Get("id").Int())
+
+	r, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer r.Close()
+	rows, err := r.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal([][]string{
+		{"id", "状态", "url"},
+		{"1", "ok", "#1"},
+		{"2", "失败", "#2"},
+	}, rows)
+
+	style, err := r.GetCellStyle("Sheet1", "B3")
+	tt.NoError(err)
+	s, err := r.GetStyle(style)
+	tt.NoError(err)
+	tt.Equal("FF0000", strings.TrimPrefix(strings.ToUpper(s.Font.Color), "#"))
+
+	ok, target, err := r.GetCellHyperLink("Sheet1", "C2")
+	tt.NoError(err)
+	tt.EqualTrue(ok)
+	tt.Equal("https://example.com/1", target)
+
+	runs, err := r.GetCellRichText("Sheet1", "A2")
+	tt.NoError(err)
+	tt.Equal(1, len(runs))
+	tt.EqualTrue(runs[0].Font != nil && runs[0].Font.Bold)
+
+	comments, err := r.GetComments("Sheet1")
+	tt.NoError(err)
+	tt.Equal(1, len(comments))
+	tt.Equal("B1", comments[0].Cell)
+}
+
+func TestWriteCellHandlerV2Comment(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data := ztype.Maps{{"id": 1, "name": "a"}, {"id": 2, "name": "b"}}
+	b, err := xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "name"}
+		wo.CommentHandler = func(sheet, cell, field string, value interface{}) *xlsx.Comment {
+			return &xlsx.Comment{Text: "from handler"}
+		}
+		wo.CellHandlerV2 = func(ctx xlsx.CellContext) (xlsx.CellResult, error) {
+			if ctx.Cell == "B2" {
+				return xlsx.CellResult{Comment: &xlsx.Comment{Text: "from v2"}}, nil
+			}
+			return xlsx.CellResult{}, nil
+		}
+	})
+	tt.NoError(err)
+
+	r, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer r.Close()
+	comments, err := r.GetComments("Sheet1")
+	tt.NoError(err)
+	tt.Equal(4, len(comments))
+	texts := map[string]string{}
+	for _, c := range comments {
+		_, ok := texts[c.Cell]
+		tt.EqualTrue(!ok)
+		texts[c.Cell] = c.Text
+	}
+	tt.Equal("from v2", texts["B2"])
+	tt.Equal("from handler", texts["A2"])
+}
+
+func TestWriteCellHandlerV2Error(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data := ztype.Maps{{"id": 1}, {"id": 2}}
+	_, err := xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.CellHandlerV2 = func(ctx xlsx.CellContext) (xlsx.CellResult, error) {
+			if ctx.Index == 1 {
+				return xlsx.CellResult{}, errors.New("bad id")
+			}
+			return xlsx.CellResult{}, nil
+		}
+	})
+	tt.EqualTrue(err != nil)
+	tt.Equal("Sheet1!A3: bad id", err.Error())
+
+	_, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.CellHandlerV2 = func(ctx xlsx.CellContext) (xlsx.CellResult, error) {
+			return xlsx.CellResult{Style: 999}, nil
+		}
+	})
+	tt.EqualTrue(err != nil)
+
+	_, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "total"}
+		wo.Formulas = map[string]string{"total": "A{row}*2"}
+		wo.CellHandlerV2 = func(ctx xlsx.CellContext) (xlsx.CellResult, error) {
+			if ctx.Field == "total" && !ctx.Header {
+				return xlsx.CellResult{Value: 0}, nil
+			}
+			return xlsx.CellResult{}, nil
+		}
+	})
+	tt.Equal("Sheet1!B2: CellResult cannot replace the value of formula or image field total", err.Error())
+}
@@ -0,0 +1,164 @@
+package xlsx
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultHeaderSeparator joins the levels of grouped header keys
+const DefaultHeaderSeparator = "."
+
+// headerGrid splits the labels into header levels, leaves are placed on the
+// level of their depth and empty below it
+func headerGrid(labels []string, sep string) [][]string {
+	if sep == "" {
+		return [][]string{labels}
+	}
+
+	parts := make([][]string, len(labels))
+	depth := 1
+	for i := range labels {
+		parts[i] = strings.Split(labels[i], sep)
+		depth = max(depth, len(parts[i]))
+	}
+
+	grid := make([][]string, depth)
+	for r := range grid {
+		grid[r] = make([]string, len(labels))
+		for i := range parts {
+			if r < len(parts[i]) {
+				grid[r][i] = parts[i][r]
+			}
+		}
+	}
+	return grid
+}
+
+// writeHeader writes the header rows, merging groups horizontally and
+// leaves down to the last header row, and returns the header cells
+func writeHeader(f *excelize.File, sheet string, labels []string, o WriteOptions) ([][]string, error) {
+	grid := headerGrid(labels, o.HeaderSeparator)
+	for r := range grid {
+		if err := f.SetSheetRow(sheet, "A"+strconv.Itoa(r+1), &grid[r]); err != nil {
+			return nil, err
+		}
+	}
+	if len(grid) == 1 {
+		return grid, nil
+	}
+	if o.AsTable != nil {
+		return nil, errors.New("tables do not support grouped headers")
+	}
+
+	for _, m := range headerMerges(labels, o.HeaderSeparator, len(grid)) {
+		if err := f.MergeCell(sheet, m[0], m[1]); err != nil {
+			return nil, err
+		}
+	}
+	return grid, nil
+}
+
+// headerMerges returns the cell ranges merged over a grouped header of depth
+// rows, groups span their columns and leaves span down to the last row
+func headerMerges(labels []string, sep string, depth int) [][2]string {
+	levels := make([]int, len(labels))
+	for i := range labels {
+		levels[i] = strings.Count(labels[i], sep) + 1
+	}
+
+	merges := [][2]string{}
+	for r := 0; r < depth; r++ {
+		for i := 0; i < len(labels); {
+			if r >= levels[i] {
+				i++
+				continue
+			}
+			if r == levels[i]-1 {
+				if r < depth-1 {
+					merges = append(merges, [2]string{ToCell(r, i), ToCell(depth-1, i)})
+				}
+				i++
+				continue
+			}
+
+			prefix := strings.Join(strings.SplitN(labels[i], sep, r+2)[:r+1], sep) + sep
+			j := i + 1
+			for j < len(labels) && levels[j] > r+1 && strings.HasPrefix(labels[j], prefix) {
+				j++
+			}
+			if j-1 > i {
+				merges = append(merges, [2]string{ToCell(r, i), ToCell(r, j-1)})
+			}
+			i = j
+		}
+	}
+	return merges
+}
+
+// mergeHeaderRows collapses the HeaderRows header rows into one row of keys
+// joined by HeaderSeparator, merged cells lend their value to every level
+// they span and vertical merges count once
+func mergeHeaderRows(rows [][]string, merges []excelize.MergeCell, o ReadOptions) [][]string {
+	if o.HeaderRows <= 1 || o.NoHeaderRow || len(rows) < o.OffsetY+o.HeaderRows {
+		return rows
+	}
+
+	sep := o.HeaderSeparator
+	if sep == "" {
+		sep = DefaultHeaderSeparator
+	}
+
+	cols := 0
+	for r := o.OffsetY; r < o.OffsetY+o.HeaderRows; r++ {
+		cols = max(cols, len(rows[r]))
+	}
+
+	type mergeRef struct {
+		value string
+		id    int
+	}
+	area := make(map[[2]int]mergeRef)
+	for id, m := range merges {
+		startCol, startRow, err := excelize.CellNameToCoordinates(m.GetStartAxis())
+		if err != nil {
+			continue
+		}
+		endCol, endRow, err := excelize.CellNameToCoordinates(m.GetEndAxis())
+		if err != nil {
+			continue
+		}
+		for r := max(startRow-1, o.OffsetY); r <= min(endRow-1, o.OffsetY+o.HeaderRows-1); r++ {
+			for c := startCol - 1; c <= min(endCol-1, cols-1); c++ {
+				area[[2]int{r, c}] = mergeRef{value: m.GetCellValue(), id: id + 1}
+			}
+		}
+	}
+
+	header := make([]string, cols)
+	for c := 0; c < cols; c++ {
+		parts := make([]string, 0, o.HeaderRows)
+		last := 0
+		for r := o.OffsetY; r < o.OffsetY+o.HeaderRows; r++ {
+			value, id := "", 0
+			if c < len(rows[r]) {
+				value = rows[r][c]
+			}
+			if m, ok := area[[2]int{r, c}]; ok {
+				value, id = m.value, m.id
+			}
+			if value != "" && (id == 0 || id != last) {
+				parts = append(parts, value)
+			}
+			last = id
+		}
+		header[c] = strings.Join(parts, sep)
+	}
+
+	merged := make([][]string, 0, len(rows)-o.HeaderRows+1)
+	merged = append(merged, rows[:o.OffsetY]...)
+	merged = append(merged, header)
+	return append(merged, rows[o.OffsetY+o.HeaderRows:]...)
+}
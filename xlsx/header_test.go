@@ -0,0 +1,72 @@
+package xlsx_test
+
+import (
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteGroupedHeader(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_grouped_header.xlsx"
+	defer os.Remove(testFile)
+
+	data := ztype.Maps{
+		{"name": "a", "2025.Q1.Revenue": 10, "2025.Q1.Cost": 4, "2025.Q2.Revenue": 12, "2025.Q2.Cost": 5, "2026.Revenue": 30, "total": 1},
+		{"name": "b", "2025.Q1.Revenue": 20, "2025.Q1.Cost": 8, "2025.Q2.Revenue": 22, "2025.Q2.Cost": 9, "2026.Revenue": 40, "total": 2},
+	}
+	fields := []string{"name", "2025.Q1.Revenue", "2025.Q1.Cost", "2025.Q2.Revenue", "2025.Q2.Cost", "2026.Revenue", "total"}
+	tt.NoError(xlsx.WriteFile(testFile, data, func(wo *xlsx.WriteOptions) {
+		wo.First = fields
+		wo.HeaderSeparator = "."
+		wo.FreezeHeader = true
+		wo.AutoWidth = true
+		wo.HeaderStyle = &excelize.Style{Font: &excelize.Font{Bold: true}}
+	}))
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+	e := f.Engine()
+
+	rows, err := e.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal(5, len(rows))
+	tt.Equal([]string{"name", "2025", "", "", "", "2026", "total"}, rows[0])
+	tt.Equal([]string{"", "Q1", "", "Q2", "", "Revenue"}, rows[1])
+	tt.Equal([]string{"", "Revenue", "Cost", "Revenue", "Cost"}, rows[2])
+	tt.Equal("a", rows[3][0])
+	tt.Equal("30", rows[3][5])
+
+	merges, err := e.GetMergeCells("Sheet1")
+	tt.NoError(err)
+	refs := make([]string, 0, len(merges))
+	for _, m := range merges {
+		refs = append(refs, m.GetStartAxis()+":"+m.GetEndAxis())
+	}
+	sort.Strings(refs)
+	tt.Equal([]string{"A1:A3", "B1:E1", "B2:C2", "D2:E2", "F2:F3", "G1:G3"}, refs)
+
+	id, err := e.GetCellStyle("Sheet1", "B1")
+	tt.NoError(err)
+	style, err := e.GetStyle(id)
+	tt.NoError(err)
+	tt.EqualTrue(style.Font.Bold)
+	tt.Equal("center", style.Alignment.Horizontal)
+
+	data2, err := f.Read(func(ro *xlsx.ReadOptions) {
+		ro.HeaderRows = 3
+	})
+	tt.NoError(err)
+	tt.Equal(2, len(data2))
+	for _, k := range fields {
+		tt.Equal(ztype.ToString(data[1][k]), ztype.ToString(data2[1][k]))
+	}
+	tt.Equal(len(fields), len(data2[0]))
+}
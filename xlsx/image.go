@@ -0,0 +1,182 @@
+package xlsx
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sohaha/zlsgo/zarray"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultImageHeight is the height in points of rows holding images
+const DefaultImageHeight = 60.0
+
+// Image is a picture embedded in a cell
+type Image struct {
+	ContentType string
+	// Extension is the file extension with the leading dot, such as .png
+	Extension string
+	Data      []byte
+}
+
+// imageTypes maps the extensions of supported images to their content types
+var imageTypes = map[string]string{
+	".bmp":  "image/bmp",
+	".emf":  "image/emf",
+	".emz":  "image/x-emz",
+	".gif":  "image/gif",
+	".jpeg": "image/jpeg",
+	".jpg":  "image/jpeg",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".wmf":  "image/wmf",
+	".wmz":  "image/x-wmz",
+}
+
+// imageExtension returns the extension of an image from its content
+func imageExtension(b []byte) string {
+	switch http.DetectContentType(b) {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "image/bmp":
+		return ".bmp"
+	}
+	if strings.Contains(string(b[:min(len(b), 512)]), "<svg") {
+		return ".svg"
+	}
+	return ""
+}
+
+// toImage resolves a file path, bytes or Image value to an image, nil and
+// empty values return false
+func toImage(v interface{}) (Image, bool, error) {
+	var img Image
+	switch val := v.(type) {
+	case nil:
+		return img, false, nil
+	case Image:
+		img = val
+	case *Image:
+		if val == nil {
+			return img, false, nil
+		}
+		img = *val
+	case []byte:
+		img.Data = val
+	case string:
+		if val == "" {
+			return img, false, nil
+		}
+		b, err := zfile.ReadFile(val)
+		if err != nil {
+			return img, false, err
+		}
+		img.Data, img.Extension = b, strings.ToLower(filepath.Ext(val))
+	default:
+		return img, false, errors.New("unsupported image value: " + ztype.ToString(v))
+	}
+
+	if len(img.Data) == 0 {
+		return img, false, nil
+	}
+	if _, ok := imageTypes[img.Extension]; !ok {
+		img.Extension = imageExtension(img.Data)
+	}
+	if img.Extension == "" {
+		return img, false, errors.New("unsupported image format")
+	}
+	return img, true, nil
+}
+
+// setRowImages inserts the images of the image fields in the given row,
+// the row is raised to the image height first so they scale to their cells
+func setRowImages(f *excelize.File, sheet string, header []string, data ztype.Map, row int, o WriteOptions) error {
+	images := make(map[int]Image, len(o.ImageFields))
+	for i := range header {
+		if !zarray.Contains(o.ImageFields, header[i]) {
+			continue
+		}
+		img, ok, err := toImage(data[header[i]])
+		if err != nil {
+			return err
+		}
+		if ok {
+			images[i] = img
+		}
+	}
+	if len(images) == 0 {
+		return nil
+	}
+
+	height := o.ImageHeight
+	if height <= 0 {
+		height = DefaultImageHeight
+	}
+	if err := f.SetRowHeight(sheet, row, height); err != nil {
+		return err
+	}
+
+	for i := range header {
+		img, ok := images[i]
+		if !ok {
+			continue
+		}
+		err := f.AddPictureFromBytes(sheet, ToCol(i)+strconv.Itoa(row), &excelize.Picture{
+			Extension: img.Extension,
+			File:      img.Data,
+			Format: &excelize.GraphicOptions{
+				AutoFit:         true,
+				LockAspectRatio: true,
+				Positioning:     "oneCell",
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cellImage returns the first picture anchored at the cell
+func cellImage(f *excelize.File, sheet, cell string) (Image, bool, error) {
+	pics, err := f.GetPictures(sheet, cell)
+	if err != nil || len(pics) == 0 {
+		return Image{}, false, err
+	}
+	ext := strings.ToLower(pics[0].Extension)
+	return Image{Data: pics[0].File, Extension: ext, ContentType: imageTypes[ext]}, true, nil
+}
+
+// readImages collects the pictures of the image fields of every row
+func readImages(f *excelize.File, sheet string, rowsMeta []rowMeta, cols []string, o ReadOptions) error {
+	for _, field := range o.ImageFields {
+		j := slices.Index(cols, field)
+		if j < 0 || (len(o.Fields) > 0 && !zarray.Contains(o.Fields, field)) {
+			continue
+		}
+		col := ToCol(o.OffsetX + j)
+		for i := range rowsMeta {
+			img, ok, err := cellImage(f, sheet, col+strconv.Itoa(rowsMeta[i].rowNum))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			rowsMeta[i].setExtra(field, img)
+		}
+	}
+	return nil
+}
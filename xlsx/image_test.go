@@ -0,0 +1,76 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func testPNG(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageFields(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_images.xlsx"
+	imageFile := "./testdata/test_image.png"
+	defer os.Remove(testFile)
+	defer os.Remove(imageFile)
+
+	pic := testPNG(t)
+	tt.NoError(os.WriteFile(imageFile, pic, 0o644))
+
+	data := ztype.Maps{
+		{"name": "a", "photo": pic},
+		{"name": "b", "photo": imageFile},
+		{"name": "c", "photo": nil},
+	}
+	tt.NoError(xlsx.WriteFile(testFile, data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"name", "photo"}
+		wo.ImageFields = []string{"photo"}
+		wo.ImageHeight = 40
+	}))
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+
+	height, err := f.Engine().GetRowHeight("Sheet1", 2)
+	tt.NoError(err)
+	tt.Equal(40.0, height)
+	height, _ = f.Engine().GetRowHeight("Sheet1", 4)
+	tt.EqualTrue(height != 40.0)
+
+	rows, err := f.Read(func(ro *xlsx.ReadOptions) {
+		ro.ImageFields = []string{"photo"}
+	})
+	tt.NoError(err)
+	tt.Equal(3, len(rows))
+	for _, i := range []int{0, 1} {
+		img, ok := rows[i]["photo"].(xlsx.Image)
+		tt.EqualTrue(ok)
+		tt.Equal("image/png", img.ContentType)
+		tt.Equal(".png", img.Extension)
+		tt.Equal(pic, img.Data)
+	}
+	tt.Equal("", rows[2].Get("photo").String())
+
+	_, err = xlsx.Write(ztype.Maps{{"photo": []byte("not an image")}}, func(wo *xlsx.WriteOptions) {
+		wo.ImageFields = []string{"photo"}
+	})
+	tt.EqualTrue(err != nil)
+}
@@ -0,0 +1,178 @@
+package xlsx
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sohaha/zlsgo/zarray"
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultLinkColor is the font color of written hyperlinks
+const DefaultLinkColor = "#0563C1"
+
+// Suffixes of the keys holding the hyperlinks and comments of read cells
+const (
+	LinkKeySuffix    = "_link"
+	CommentKeySuffix = "_comment"
+)
+
+type (
+	// Link is a hyperlink value, URLs starting with # link to a location in
+	// the workbook such as #Sheet2!A1
+	Link struct {
+		Text    string
+		URL     string
+		Tooltip string
+	}
+	// Comment is the note attached to a cell
+	Comment struct {
+		Author string
+		Text   string
+	}
+)
+
+// text returns the display text of the link, the URL when it has none
+func (l Link) text() string {
+	if l.Text == "" {
+		return l.URL
+	}
+	return l.Text
+}
+
+// setLink turns the cell into a hyperlink styled as a link on top of the
+// style of its column, locked cells stay locked whatever the column
+func setLink(f *excelize.File, sheet, cell string, col int, l Link, styles *numFmtStyles, locked bool) error {
+	if err := addLink(f, sheet, cell, l); err != nil {
+		return err
+	}
+
+	style, err := styles.link(col, locked)
+	if err != nil {
+		return err
+	}
+	return f.SetCellStyle(sheet, cell, cell, style)
+}
+
+// addLink adds the hyperlink of the cell, leaving its value and style
+func addLink(f *excelize.File, sheet, cell string, l Link) error {
+	target, typ := l.URL, "External"
+	if strings.HasPrefix(target, "#") {
+		target, typ = target[1:], "Location"
+	}
+	opts := excelize.HyperlinkOpts{}
+	if l.Tooltip != "" {
+		opts.Tooltip = &l.Tooltip
+	}
+	return f.SetCellHyperLink(sheet, cell, target, typ, opts)
+}
+
+func (s *numFmtStyles) link(col int, locked bool) (int, error) {
+	base, ok := s.columns[col]
+	if !ok {
+		col = -1
+	}
+	key := [2]interface{}{col, Link{}}
+	if locked {
+		key[1] = excelize.Protection{Locked: true}
+	}
+	if id, ok := s.styles[key]; ok {
+		return id, nil
+	}
+
+	style := &excelize.Style{}
+	if base != nil {
+		b := *base
+		style = &b
+	}
+	font := excelize.Font{}
+	if style.Font != nil {
+		font = *style.Font
+	}
+	font.Color, font.Underline = DefaultLinkColor, "single"
+	style.Font = &font
+	if locked {
+		style = protectStyle(style, true)
+	}
+	id, err := s.f.NewStyle(style)
+	if err != nil {
+		return 0, err
+	}
+	s.styles[key] = id
+	return id, nil
+}
+
+// setRowComments attaches the comments returned by CommentHandler to the
+// cells of the row, cells commented by CellHandlerV2 keep that comment only
+func setRowComments(f *excelize.File, sheet string, header []string, values []interface{}, results []CellResult, row int, o WriteOptions) error {
+	for i := range header {
+		if c := results[i].Comment; c != nil && c.Text != "" {
+			continue
+		}
+		cell := ToCol(i) + strconv.Itoa(row)
+		c := o.CommentHandler(sheet, cell, header[i], values[i])
+		if c == nil || c.Text == "" {
+			continue
+		}
+		err := f.AddComment(sheet, excelize.Comment{Cell: cell, Author: c.Author, Text: c.Text})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLinksAndComments collects the hyperlinks and comments of the cells
+// under the keys of their fields with LinkKeySuffix and CommentKeySuffix
+func readLinksAndComments(f *excelize.File, sheet string, rowsMeta []rowMeta, cols []string, o ReadOptions) error {
+	if !o.WithHyperlinks && !o.WithComments {
+		return nil
+	}
+
+	comments := map[string]Comment{}
+	if o.WithComments {
+		list, err := f.GetComments(sheet)
+		if err != nil {
+			return err
+		}
+		for _, c := range list {
+			comments[c.Cell] = Comment{Author: c.Author, Text: commentText(c)}
+		}
+	}
+
+	for j := range cols {
+		if len(o.Fields) > 0 && !zarray.Contains(o.Fields, cols[j]) {
+			continue
+		}
+		col := ToCol(o.OffsetX + j)
+		for i := range rowsMeta {
+			cell := col + strconv.Itoa(rowsMeta[i].rowNum)
+			if o.WithHyperlinks {
+				ok, target, err := f.GetCellHyperLink(sheet, cell)
+				if err != nil {
+					return err
+				}
+				if ok {
+					rowsMeta[i].setExtra(cols[j]+LinkKeySuffix, target)
+				}
+			}
+			if c, ok := comments[cell]; ok {
+				rowsMeta[i].setExtra(cols[j]+CommentKeySuffix, c)
+			}
+		}
+	}
+	return nil
+}
+
+// commentText returns the text of a comment without the author prefix
+// written by Excel in the first run
+func commentText(c excelize.Comment) string {
+	if c.Text != "" || len(c.Paragraph) == 0 {
+		return c.Text
+	}
+	var b strings.Builder
+	for _, p := range c.Paragraph {
+		b.WriteString(p.Text)
+	}
+	return strings.TrimPrefix(b.String(), c.Author+":\n")
+}
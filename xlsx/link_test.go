@@ -0,0 +1,97 @@
+package xlsx_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestLinksAndComments(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_links.xlsx"
+	defer os.Remove(testFile)
+
+	data := ztype.Maps{
+		{"id": 1, "page": xlsx.Link{Text: "订单 1", URL: "https://example.com/orders/1", Tooltip: "打开"}, "status": "ok"},
+		{"id": 2, "page": &xlsx.Link{URL: "#Sheet1!A1"}, "status": "rejected"},
+		{"id": 3, "page": nil, "status": "ok"},
+	}
+	tt.NoError(xlsx.WriteFile(testFile, data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "page", "status"}
+		wo.CommentHandler = func(sheet, cell, field string, value interface{}) *xlsx.Comment {
+			if field == "status" && value == "rejected" {
+				return &xlsx.Comment{Author: "reviewer", Text: "金额不符"}
+			}
+			return nil
+		}
+	}))
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+	e := f.Engine()
+
+	ok, target, err := e.GetCellHyperLink("Sheet1", "B2")
+	tt.NoError(err)
+	tt.EqualTrue(ok)
+	tt.Equal("https://example.com/orders/1", target)
+	id, _ := e.GetCellStyle("Sheet1", "B2")
+	style, err := e.GetStyle(id)
+	tt.NoError(err)
+	tt.Equal("single", style.Font.Underline)
+
+	rows, err := f.Read()
+	tt.NoError(err)
+	tt.Equal("订单 1", rows[0].Get("page").String())
+	tt.Equal("#Sheet1!A1", rows[1].Get("page").String())
+	_, has := rows[0]["page"+xlsx.LinkKeySuffix]
+	tt.EqualTrue(!has)
+
+	rows, err = f.Read(func(ro *xlsx.ReadOptions) {
+		ro.WithHyperlinks = true
+		ro.WithComments = true
+	})
+	tt.NoError(err)
+	tt.Equal(3, len(rows))
+	tt.Equal("https://example.com/orders/1", rows[0].Get("page"+xlsx.LinkKeySuffix).String())
+	tt.Equal("Sheet1!A1", rows[1].Get("page"+xlsx.LinkKeySuffix).String())
+	_, has = rows[2]["page"+xlsx.LinkKeySuffix]
+	tt.EqualTrue(!has)
+	tt.Equal(xlsx.Comment{Author: "reviewer", Text: "金额不符"}, rows[1]["status"+xlsx.CommentKeySuffix])
+	_, has = rows[0]["status"+xlsx.CommentKeySuffix]
+	tt.EqualTrue(!has)
+
+	comments, err := e.GetComments("Sheet1")
+	tt.NoError(err)
+	tt.Equal(1, len(comments))
+	tt.Equal("C3", comments[0].Cell)
+
+	// links added in memory on another sheet are read without saving
+	_, err = e.NewSheet("Links")
+	tt.NoError(err)
+	tt.NoError(e.SetSheetRow("Links", "A1", &[]string{"name", "url"}))
+	for i := 2; i <= 101; i++ {
+		cell := "B" + strconv.Itoa(i)
+		tt.NoError(e.SetSheetRow("Links", "A"+strconv.Itoa(i), &[]string{strconv.Itoa(i), "link"}))
+		if i%2 == 0 {
+			tt.NoError(e.SetCellHyperLink("Links", cell, "https://example.com/"+strconv.Itoa(i), "External"))
+		}
+	}
+	rows, err = f.Read(func(ro *xlsx.ReadOptions) {
+		ro.Sheet = "Links"
+		ro.WithHyperlinks = true
+	})
+	tt.NoError(err)
+	tt.Equal(100, len(rows))
+	tt.Equal("https://example.com/2", rows[0].Get("url"+xlsx.LinkKeySuffix).String())
+	_, has = rows[1]["url"+xlsx.LinkKeySuffix]
+	tt.EqualTrue(!has)
+	tt.Equal("https://example.com/100", rows[98].Get("url"+xlsx.LinkKeySuffix).String())
+	_, has = rows[0]["name"+xlsx.LinkKeySuffix]
+	tt.EqualTrue(!has)
+}
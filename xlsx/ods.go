@@ -0,0 +1,775 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// OpenDocument namespaces
+const (
+	odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+	odsNSOffice = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	odsNSStyle  = "urn:oasis:names:tc:opendocument:xmlns:style:1.0"
+	odsNSTable  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsNSText   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+var (
+	zipMagic = []byte("PK\x03\x04")
+
+	errNotODS = errors.New("not an OpenDocument spreadsheet")
+)
+
+type (
+	odsCell struct {
+		value   interface{}
+		formula string
+		numFmt  string
+		rowSpan int
+		colSpan int
+	}
+	odsSheet struct {
+		name   string
+		style  string
+		cells  map[[2]int]odsCell
+		hidden bool
+	}
+)
+
+// isODSFile reports whether the file is a zip package whose mimetype is an
+// OpenDocument spreadsheet
+func isODSFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(zipMagic))
+	if _, err = io.ReadFull(f, magic); err != nil || !bytes.Equal(magic, zipMagic) {
+		return false
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return false
+	}
+	defer r.Close()
+
+	for _, file := range r.File {
+		if file.Name != "mimetype" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return false
+		}
+		b, _ := io.ReadAll(io.LimitReader(rc, 128))
+		_ = rc.Close()
+		return strings.TrimSpace(string(b)) == odsMimeType
+	}
+	return false
+}
+
+// openODS converts an OpenDocument spreadsheet into an in-memory workbook
+// so that it can be read with the same API as xlsx files
+func openODS(path string) (*excelize.File, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	for _, file := range r.File {
+		if file.Name != "content.xml" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		sheets, err := parseODSContent(rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		return odsToFile(sheets)
+	}
+	return nil, errNotODS
+}
+
+func odsAttr(se xml.StartElement, space, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func odsRepeat(se xml.StartElement, local string) int {
+	n, err := strconv.Atoi(odsAttr(se, odsNSTable, local))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+func parseODSContent(r io.Reader) ([]*odsSheet, error) {
+	dec := xml.NewDecoder(r)
+
+	var (
+		sheets       []*odsSheet
+		sheet        *odsSheet
+		hiddenStyles = map[string]bool{}
+		tableStyle   string
+		row, col     int
+		rowCells     map[int]odsCell
+		rowRepeat    int
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == odsNSStyle && t.Name.Local == "style":
+				tableStyle = ""
+				if odsAttr(t, odsNSStyle, "family") == "table" {
+					tableStyle = odsAttr(t, odsNSStyle, "name")
+				}
+			case t.Name.Space == odsNSStyle && t.Name.Local == "table-properties":
+				if tableStyle != "" && odsAttr(t, odsNSTable, "display") == "false" {
+					hiddenStyles[tableStyle] = true
+				}
+			case t.Name.Space != odsNSTable:
+			case t.Name.Local == "table":
+				if sheet != nil {
+					// nested tables are not supported
+					if err = dec.Skip(); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				sheet = &odsSheet{
+					name:  odsAttr(t, odsNSTable, "name"),
+					style: odsAttr(t, odsNSTable, "style-name"),
+					cells: map[[2]int]odsCell{},
+				}
+				row = 0
+			case t.Name.Local == "table-row" && sheet != nil:
+				col, rowCells = 0, map[int]odsCell{}
+				rowRepeat = odsRepeat(t, "number-rows-repeated")
+			case (t.Name.Local == "table-cell" || t.Name.Local == "covered-table-cell") && rowCells != nil:
+				repeat := odsRepeat(t, "number-columns-repeated")
+				cell, err := readODSCell(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				if cell.value != nil || cell.rowSpan > 1 || cell.colSpan > 1 {
+					for i := 0; i < repeat && col+i < excelize.MaxColumns; i++ {
+						rowCells[col+i] = cell
+					}
+				}
+				col += repeat
+			}
+		case xml.EndElement:
+			if t.Name.Space != odsNSTable || sheet == nil {
+				continue
+			}
+			switch t.Name.Local {
+			case "table-row":
+				if rowCells == nil {
+					continue
+				}
+				// repeated rows are only expanded when they carry values
+				if len(rowCells) > 0 {
+					for i := 0; i < rowRepeat && row+i < excelize.TotalRows; i++ {
+						for c, cell := range rowCells {
+							sheet.cells[[2]int{row + i, c}] = cell
+						}
+					}
+				}
+				row += rowRepeat
+				rowCells = nil
+			case "table":
+				sheet.hidden = hiddenStyles[sheet.style]
+				sheets = append(sheets, sheet)
+				sheet = nil
+			}
+		}
+	}
+
+	if len(sheets) == 0 {
+		return nil, errNotODS
+	}
+	return sheets, nil
+}
+
+// readODSCell reads the typed value of a cell and consumes it up to its end element
+func readODSCell(dec *xml.Decoder, se xml.StartElement) (odsCell, error) {
+	cell := odsCell{
+		formula: odsAttr(se, odsNSTable, "formula"),
+		rowSpan: odsRepeat(se, "number-rows-spanned"),
+		colSpan: odsRepeat(se, "number-columns-spanned"),
+	}
+	if se.Name.Local == "covered-table-cell" {
+		cell.rowSpan, cell.colSpan = 1, 1
+	}
+
+	var (
+		paragraphs []string
+		text       strings.Builder
+		inText     bool
+	)
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return cell, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if t.Name.Space == odsNSOffice && t.Name.Local == "annotation" {
+				if err = dec.Skip(); err != nil {
+					return cell, err
+				}
+				depth--
+				continue
+			}
+			if t.Name.Space != odsNSText {
+				continue
+			}
+			switch t.Name.Local {
+			case "p", "h":
+				if !inText {
+					inText = true
+					text.Reset()
+				}
+			case "s":
+				n, err := strconv.Atoi(odsAttr(t, odsNSText, "c"))
+				if err != nil || n < 1 {
+					n = 1
+				}
+				text.WriteString(strings.Repeat(" ", n))
+			case "tab":
+				text.WriteByte('\t')
+			case "line-break":
+				text.WriteByte('\n')
+			}
+		case xml.EndElement:
+			depth--
+			if t.Name.Space == odsNSText && (t.Name.Local == "p" || t.Name.Local == "h") && inText {
+				paragraphs = append(paragraphs, text.String())
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+	content := strings.Join(paragraphs, "\n")
+
+	switch odsAttr(se, odsNSOffice, "value-type") {
+	case "float", "currency":
+		if n, err := strconv.ParseFloat(odsAttr(se, odsNSOffice, "value"), 64); err == nil {
+			cell.value = n
+		}
+	case "percentage":
+		if n, err := strconv.ParseFloat(odsAttr(se, odsNSOffice, "value"), 64); err == nil {
+			cell.value, cell.numFmt = n, "0.00%"
+		}
+	case "date":
+		if t, ok := parseODSDate(odsAttr(se, odsNSOffice, "date-value")); ok {
+			cell.value, cell.numFmt = timeToExcel(t), "yyyy-mm-dd"
+			if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 {
+				cell.numFmt = "yyyy-mm-dd hh:mm:ss"
+			}
+		}
+	case "time":
+		if d, ok := parseODSDuration(odsAttr(se, odsNSOffice, "time-value")); ok {
+			cell.value, cell.numFmt = d.Hours()/24, "hh:mm:ss"
+		}
+	case "boolean":
+		cell.value = odsAttr(se, odsNSOffice, "boolean-value") == "true"
+	case "string", "":
+		if content != "" {
+			cell.value = content
+		}
+	}
+	if cell.value == nil && content != "" {
+		cell.value = content
+	}
+	return cell, nil
+}
+
+func parseODSDate(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseODSDuration parses the ISO 8601 durations used for time values (e.g., PT12H30M00S)
+func parseODSDuration(s string) (time.Duration, bool) {
+	s, ok := strings.CutPrefix(s, "PT")
+	if !ok {
+		return 0, false
+	}
+
+	var d time.Duration
+	for s != "" {
+		i := strings.IndexAny(s, "HMS")
+		if i <= 0 {
+			return 0, false
+		}
+		n, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil {
+			return 0, false
+		}
+		switch s[i] {
+		case 'H':
+			d += time.Duration(n * float64(time.Hour))
+		case 'M':
+			d += time.Duration(n * float64(time.Minute))
+		case 'S':
+			d += time.Duration(n * float64(time.Second))
+		}
+		s = s[i+1:]
+	}
+	return d, true
+}
+
+// timeToExcel converts a time to an Excel serial date of the 1900 date system
+func timeToExcel(t time.Time) float64 {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return t.Sub(epoch).Hours() / 24
+}
+
+// odsFormula converts an OpenFormula expression to the A1 syntax of Excel
+func odsFormula(formula string) string {
+	if f, ok := strings.CutPrefix(formula, "msoxl:="); ok {
+		return f
+	}
+	f, ok := strings.CutPrefix(formula, "of:=")
+	if !ok {
+		return ""
+	}
+
+	var (
+		b        strings.Builder
+		inString bool
+	)
+	for i := 0; i < len(f); i++ {
+		c := f[i]
+		switch {
+		case c == '"':
+			inString = !inString
+			b.WriteByte(c)
+		case inString:
+			b.WriteByte(c)
+		case c == '[':
+			end := strings.IndexByte(f[i:], ']')
+			if end < 0 {
+				b.WriteString(f[i:])
+				return b.String()
+			}
+			b.WriteString(odsRef(f[i+1 : i+end]))
+			i += end
+		case c == ';':
+			b.WriteByte(',')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// odsRef converts a reference such as .A1, $Sheet2.A1 or .A1:.B2 to A1 syntax
+func odsRef(ref string) string {
+	parts := strings.Split(ref, ":")
+	for i, part := range parts {
+		j := strings.LastIndexByte(part, '.')
+		if j < 0 {
+			continue
+		}
+		sheet := strings.TrimPrefix(part[:j], "$")
+		if sheet == "" || i > 0 {
+			parts[i] = part[j+1:]
+		} else {
+			parts[i] = sheet + "!" + part[j+1:]
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+func odsToFile(sheets []*odsSheet) (*excelize.File, error) {
+	f := excelize.NewFile()
+	styles := map[string]int{}
+	for i, sheet := range sheets {
+		var err error
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), sheet.name)
+		} else {
+			_, err = f.NewSheet(sheet.name)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for pos, c := range sheet.cells {
+			cell := ToCell(pos[0], pos[1])
+			switch v := c.value.(type) {
+			case float64:
+				err = f.SetCellFloat(sheet.name, cell, v, -1, 64)
+				if err == nil && c.numFmt != "" {
+					style, ok := styles[c.numFmt]
+					if !ok {
+						numFmt := c.numFmt
+						style, err = f.NewStyle(&excelize.Style{CustomNumFmt: &numFmt})
+						styles[c.numFmt] = style
+					}
+					if err == nil {
+						err = f.SetCellStyle(sheet.name, cell, cell, style)
+					}
+				}
+			case bool:
+				err = f.SetCellBool(sheet.name, cell, v)
+			case string:
+				err = f.SetCellStr(sheet.name, cell, v)
+			}
+			if err == nil && c.formula != "" {
+				if formula := odsFormula(c.formula); formula != "" {
+					err = f.SetCellFormula(sheet.name, cell, formula)
+				}
+			}
+			if err == nil && (c.rowSpan > 1 || c.colSpan > 1) {
+				err = f.MergeCell(sheet.name, cell, ToCell(pos[0]+c.rowSpan-1, pos[1]+c.colSpan-1))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, sheet := range sheets {
+		if sheet.hidden {
+			_ = f.SetSheetVisible(sheet.name, false)
+		}
+	}
+	return f, nil
+}
+
+const (
+	odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+ <manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="application/vnd.oasis.opendocument.spreadsheet"/>
+ <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+ <manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>
+ <manifest:file-entry manifest:full-path="meta.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>
+`
+	odsMeta = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-meta xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" office:version="1.2">
+ <office:meta><meta:generator>zlsgo/office</meta:generator></office:meta>
+</office:document-meta>
+`
+	odsStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" office:version="1.2">
+ <office:styles>
+  <style:default-style style:family="table-cell"/>
+  <style:style style:name="Default" style:family="table-cell"/>
+ </office:styles>
+</office:document-styles>
+`
+	odsContentHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0" xmlns:of="urn:oasis:names:tc:opendocument:xmlns:of:1.2" xmlns:msoxl="http://schemas.microsoft.com/office/excel/formula" office:version="1.2">
+ <office:automatic-styles>
+  <style:style style:name="ta1" style:family="table"><style:table-properties table:display="true"/></style:style>
+  <style:style style:name="ta2" style:family="table"><style:table-properties table:display="false"/></style:style>
+  <number:date-style style:name="N1"><number:year number:style="long"/><number:text>-</number:text><number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/></number:date-style>
+  <number:date-style style:name="N2"><number:year number:style="long"/><number:text>-</number:text><number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/><number:text> </number:text><number:hours number:style="long"/><number:text>:</number:text><number:minutes number:style="long"/><number:text>:</number:text><number:seconds number:style="long"/></number:date-style>
+  <style:style style:name="ce1" style:family="table-cell" style:parent-style-name="Default" style:data-style-name="N1"/>
+  <style:style style:name="ce2" style:family="table-cell" style:parent-style-name="Default" style:data-style-name="N2"/>
+ </office:automatic-styles>
+ <office:body>
+  <office:spreadsheet>
+`
+	odsContentFooter = `  </office:spreadsheet>
+ </office:body>
+</office:document-content>
+`
+)
+
+// encodeODS serializes every sheet of the workbook as an OpenDocument spreadsheet
+func (x *Xlsx) encodeODS() ([]byte, error) {
+	var content bytes.Buffer
+	content.WriteString(odsContentHeader)
+	for _, sheet := range x.Sheets() {
+		if err := x.encodeODSTable(&content, sheet); err != nil {
+			return nil, err
+		}
+	}
+	content.WriteString(odsContentFooter)
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	// the mimetype must be the first entry and stored uncompressed
+	mimetype := []byte(odsMimeType)
+	fw, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(mimetype),
+		CompressedSize64:   uint64(len(mimetype)),
+		UncompressedSize64: uint64(len(mimetype)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = fw.Write(mimetype); err != nil {
+		return nil, err
+	}
+
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{"META-INF/manifest.xml", []byte(odsManifest)},
+		{"meta.xml", []byte(odsMeta)},
+		{"styles.xml", []byte(odsStyles)},
+		{"content.xml", content.Bytes()},
+	} {
+		if fw, err = w.Create(file.name); err != nil {
+			return nil, err
+		}
+		if _, err = fw.Write(file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (x *Xlsx) encodeODSTable(w *bytes.Buffer, sheet SheetInfo) error {
+	style := "ta1"
+	if sheet.Visible != SheetVisible {
+		style = "ta2"
+	}
+	w.WriteString(`   <table:table table:name="` + xmlEscape(sheet.Name) + `" table:style-name="` + style + `">`)
+
+	endRow, endCol := -1, -1
+	if sheet.Dimension != "" {
+		_, _, endRow, endCol, _ = parseRange(sheet.Dimension)
+	}
+	if endCol > 0 {
+		w.WriteString(`<table:table-column table:number-columns-repeated="` + strconv.Itoa(endCol+1) + `"/>`)
+	} else {
+		w.WriteString(`<table:table-column/>`)
+	}
+
+	spans := map[[2]int][2]int{}
+	covered := map[[2]int]bool{}
+	merges, err := x.f.GetMergeCells(sheet.Name)
+	if err != nil {
+		return err
+	}
+	for _, m := range merges {
+		r1, c1, r2, c2, err := parseRange(m.GetStartAxis() + ":" + m.GetEndAxis())
+		if err != nil {
+			continue
+		}
+		spans[[2]int{r1, c1}] = [2]int{r2 - r1 + 1, c2 - c1 + 1}
+		for r := r1; r <= r2; r++ {
+			for c := c1; c <= c2; c++ {
+				if r != r1 || c != c1 {
+					covered[[2]int{r, c}] = true
+				}
+			}
+		}
+	}
+
+	emptyRows := 0
+	flushRows := func() {
+		if emptyRows > 0 {
+			w.WriteString(`<table:table-row`)
+			if emptyRows > 1 {
+				w.WriteString(` table:number-rows-repeated="` + strconv.Itoa(emptyRows) + `"`)
+			}
+			w.WriteString(`><table:table-cell/></table:table-row>`)
+			emptyRows = 0
+		}
+	}
+
+	var row bytes.Buffer
+	for r := 0; r <= endRow; r++ {
+		row.Reset()
+		emptyCells, hasValue := 0, false
+		for c := 0; c <= endCol; c++ {
+			pos := [2]int{r, c}
+			cell, ok := x.encodeODSCell(sheet.Name, r, c, spans[pos], covered[pos])
+			if !ok {
+				emptyCells++
+				continue
+			}
+			if emptyCells > 0 {
+				row.WriteString(odsEmptyCells(emptyCells))
+				emptyCells = 0
+			}
+			row.WriteString(cell)
+			hasValue = true
+		}
+		if !hasValue {
+			emptyRows++
+			continue
+		}
+		flushRows()
+		w.WriteString(`<table:table-row>`)
+		w.Write(row.Bytes())
+		w.WriteString(`</table:table-row>`)
+	}
+	flushRows()
+	if endRow < 0 {
+		w.WriteString(`<table:table-row><table:table-cell/></table:table-row>`)
+	}
+
+	w.WriteString("</table:table>\n")
+	return nil
+}
+
+func odsEmptyCells(n int) string {
+	if n == 1 {
+		return `<table:table-cell/>`
+	}
+	return `<table:table-cell table:number-columns-repeated="` + strconv.Itoa(n) + `"/>`
+}
+
+// encodeODSCell returns the markup of a cell, or false when it is empty
+func (x *Xlsx) encodeODSCell(sheet string, r, c int, span [2]int, covered bool) (string, bool) {
+	cell := ToCell(r, c)
+	value := x.cellValue(sheet, cell)
+	formula, _ := x.f.GetCellFormula(sheet, cell)
+	if value == nil && formula == "" && span == [2]int{} && !covered {
+		return "", false
+	}
+
+	tag := "table:table-cell"
+	if covered {
+		tag = "table:covered-table-cell"
+	}
+
+	var b strings.Builder
+	b.WriteString("<" + tag)
+	if span[0] > 1 || span[1] > 1 {
+		b.WriteString(` table:number-rows-spanned="` + strconv.Itoa(span[0]) + `" table:number-columns-spanned="` + strconv.Itoa(span[1]) + `"`)
+	}
+	if formula != "" {
+		b.WriteString(` table:formula="msoxl:=` + xmlEscape(formula) + `"`)
+	}
+
+	text := ""
+	switch v := value.(type) {
+	case nil:
+	case bool:
+		text = strings.ToUpper(strconv.FormatBool(v))
+		b.WriteString(` office:value-type="boolean" office:boolean-value="` + strconv.FormatBool(v) + `"`)
+	case time.Time:
+		style, layout := "ce1", time.DateOnly
+		if v.Hour() != 0 || v.Minute() != 0 || v.Second() != 0 {
+			style, layout = "ce2", time.DateTime
+		}
+		text = v.Format(layout)
+		b.WriteString(` table:style-name="` + style + `" office:value-type="date" office:date-value="` + v.Format("2006-01-02T15:04:05") + `"`)
+	case int64, float64:
+		text, _ = x.f.GetCellValue(sheet, cell)
+		b.WriteString(` office:value-type="float" office:value="` + formatNumber(v) + `"`)
+	default:
+		text = v.(string)
+		b.WriteString(` office:value-type="string"`)
+	}
+
+	if text == "" {
+		b.WriteString("/>")
+		return b.String(), true
+	}
+	b.WriteString(">")
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString("<text:p>" + odsText(line) + "</text:p>")
+	}
+	b.WriteString("</" + tag + ">")
+	return b.String(), true
+}
+
+func formatNumber(v interface{}) string {
+	switch n := v.(type) {
+	case int64:
+		return strconv.FormatInt(n, 10)
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return ""
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// odsText escapes a paragraph, keeping runs of spaces and tabs that
+// OpenDocument would otherwise collapse
+func odsText(s string) string {
+	var b strings.Builder
+	spaces := 0
+	flush := func() {
+		if spaces == 0 {
+			return
+		}
+		b.WriteByte(' ')
+		if spaces > 1 {
+			b.WriteString(`<text:s text:c="` + strconv.Itoa(spaces-1) + `"/>`)
+		}
+		spaces = 0
+	}
+	for i, r := range s {
+		switch r {
+		case ' ':
+			if i == 0 {
+				b.WriteString(`<text:s/>`)
+				continue
+			}
+			spaces++
+		case '\t':
+			flush()
+			b.WriteString(`<text:tab/>`)
+		default:
+			flush()
+			b.WriteString(xmlEscape(string(r)))
+		}
+	}
+	flush()
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteODS(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_write.ods"
+	defer os.Remove(testFile)
+
+	date := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	data := ztype.Maps{
+		{"name": "张三", "age": 18, "score": 95.5, "vip": true, "joined": date, "remark": "a  b\nc"},
+		{"name": "<李四>", "age": 20, "score": 80, "vip": false, "joined": date.Add(36 * time.Hour), "remark": nil},
+	}
+	tt.NoError(xlsx.WriteFile(testFile, data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"name", "age"}
+		wo.Sheet = "数据"
+	}))
+	tt.Equal(xlsx.FormatODS, xlsx.DetectFormat(testFile))
+
+	r, err := zip.OpenReader(testFile)
+	tt.NoError(err)
+	tt.Equal("mimetype", r.File[0].Name)
+	tt.Equal(zip.Store, r.File[0].Method)
+	_ = r.Close()
+
+	rows, err := xlsx.Read(testFile, func(ro *xlsx.ReadOptions) {
+		ro.Sheet = "数据"
+	})
+	tt.NoError(err)
+	tt.Equal(2, len(rows))
+	tt.Equal("张三", rows[0].Get("name").String())
+	tt.Equal(18, rows[0].Get("age").Int())
+	tt.Equal(95.5, rows[0].Get("score").Float64())
+	tt.Equal("TRUE", rows[0].Get("vip").String())
+	tt.Equal("2025-01-02", rows[0].Get("joined").String())
+	tt.Equal("a  b\nc", rows[0].Get("remark").String())
+	tt.Equal("<李四>", rows[1].Get("name").String())
+	tt.Equal("2025-01-03 12:00:00", rows[1].Get("joined").String())
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+	joined, ok := f.Get("数据", "C3").Value().(time.Time)
+	tt.EqualTrue(ok)
+	tt.Equal(date.Add(36*time.Hour), joined)
+	tt.Equal(false, f.Get("数据", "F3").Value())
+
+	header, _, err := xlsx.Load(testFile, "", func(ro *xlsx.ReadOptions) {
+		ro.Sheet = "数据"
+	})
+	tt.NoError(err)
+	tt.Equal([]string{"name", "age", "joined", "remark", "score", "vip"}, header)
+}
+
+func TestReadODS(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:of="urn:oasis:names:tc:opendocument:xmlns:of:1.2" office:version="1.2">
+<office:automatic-styles>
+<style:style style:name="ta1" style:family="table"><style:table-properties table:display="true"/></style:style>
+<style:style style:name="ta2" style:family="table"><style:table-properties table:display="false"/></style:style>
+</office:automatic-styles>
+<office:body><office:spreadsheet>
+<table:table table:name="Users" table:style-name="ta1">
+<table:table-column table:number-columns-repeated="1024"/>
+<table:table-header-rows>
+<table:table-row>
+<table:table-cell office:value-type="string"><text:p>name</text:p></table:table-cell>
+<table:table-cell office:value-type="string"><text:p>rate</text:p></table:table-cell>
+<table:table-cell office:value-type="string"><text:p>at</text:p></table:table-cell>
+<table:table-cell office:value-type="string"><text:p>total</text:p></table:table-cell>
+<table:table-cell table:number-columns-repeated="1020"/>
+</table:table-row>
+</table:table-header-rows>
+<table:table-row table:number-rows-repeated="2">
+<table:table-cell office:value-type="string"><text:p>a<text:s text:c="2"/>b<text:span>c</text:span></text:p><office:annotation><text:p>note</text:p></office:annotation></table:table-cell>
+<table:table-cell office:value-type="percentage" office:value="0.25"><text:p>25%</text:p></table:table-cell>
+<table:table-cell office:value-type="time" office:time-value="PT12H30M00S"><text:p>12:30</text:p></table:table-cell>
+<table:table-cell table:formula="of:=SUM([.B2:.B3];[Other.A1])" office:value-type="float" office:value="3"><text:p>3</text:p></table:table-cell>
+</table:table-row>
+<table:table-row table:number-rows-repeated="3"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+<table:table-row>
+<table:table-cell table:number-columns-spanned="2" table:number-rows-spanned="1" office:value-type="string"><text:p>merged</text:p><text:p>two lines</text:p></table:table-cell>
+<table:covered-table-cell/>
+<table:table-cell table:number-columns-repeated="2" office:value-type="float" office:value="7"><text:p>7</text:p></table:table-cell>
+</table:table-row>
+<table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table>
+<table:table table:name="Other" table:style-name="ta2">
+<table:table-row><table:table-cell office:value-type="boolean" office:boolean-value="true"><text:p>TRUE</text:p></table:table-cell></table:table-row>
+</table:table>
+</office:spreadsheet></office:body>
+</office:document-content>`
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	fw, err := w.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	tt.NoError(err)
+	_, _ = fw.Write([]byte("application/vnd.oasis.opendocument.spreadsheet"))
+	fw, err = w.Create("content.xml")
+	tt.NoError(err)
+	_, _ = fw.Write([]byte(content))
+	tt.NoError(w.Close())
+
+	testFile := "./testdata/test_read_ods.dat"
+	defer os.Remove(testFile)
+	tt.NoError(zfile.WriteFile(testFile, buf.Bytes()))
+	tt.Equal(xlsx.FormatODS, xlsx.DetectFormat(testFile))
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+
+	sheets := f.Sheets()
+	tt.Equal(2, len(sheets))
+	tt.Equal("A1:D7", sheets[0].Dimension)
+	tt.Equal(xlsx.SheetHidden, sheets[1].Visible)
+
+	data, err := f.Read(func(ro *xlsx.ReadOptions) {
+		ro.RemoveEmptyRow = true
+	})
+	tt.NoError(err)
+	tt.Equal(3, len(data))
+	tt.Equal("a  bc", data[0].Get("name").String())
+	tt.Equal("a  bc", data[1].Get("name").String())
+	tt.Equal("25.00%", data[0].Get("rate").String())
+	tt.Equal("12:30:00", data[0].Get("at").String())
+	tt.Equal(3, data[1].Get("total").Int())
+	tt.Equal("merged\ntwo lines", data[2].Get("name").String())
+	tt.Equal(7, data[2].Get("at").Int())
+	tt.Equal(7, data[2].Get("total").Int())
+
+	formula, err := f.Engine().GetCellFormula("Users", "D2")
+	tt.NoError(err)
+	tt.Equal("SUM(B2:B3,Other!A1)", formula)
+
+	merges, err := f.Engine().GetMergeCells("Users")
+	tt.NoError(err)
+	tt.Equal(1, len(merges))
+	tt.Equal("A7", merges[0].GetStartAxis())
+	tt.Equal("B7", merges[0].GetEndAxis())
+
+	tt.Equal(true, f.Get("Other", "A1").Value())
+
+	out := "./testdata/test_read_ods.ods"
+	defer os.Remove(out)
+	tt.NoError(f.SaveAs(out))
+	b, err := zfile.ReadFile(out)
+	tt.NoError(err)
+	tt.EqualTrue(len(b) > 0)
+
+	again, err := xlsx.Open(out)
+	tt.NoError(err)
+	defer again.Close()
+	tt.Equal(xlsx.SheetHidden, again.Sheets()[1].Visible)
+	formula, _ = again.Engine().GetCellFormula("Users", "D2")
+	tt.Equal("SUM(B2:B3,Other!A1)", formula)
+	data, err = again.Read(func(ro *xlsx.ReadOptions) {
+		ro.RemoveEmptyRow = true
+	})
+	tt.NoError(err)
+	tt.Equal("merged\ntwo lines", data[2].Get("name").String())
+	tt.EqualTrue(strings.HasPrefix(data[0].Get("at").String(), "1899-12-30"))
+}
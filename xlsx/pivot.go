@@ -0,0 +1,406 @@
+package xlsx
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultPivotStyle is the style of pivot tables written with Pivots
+const DefaultPivotStyle = "PivotStyleLight16"
+
+type (
+	// PivotValue aggregates a field: sum, average (avg), count, countNums,
+	// max, min, product, stdDev, stdDevp, var or varp, sum by default
+	PivotValue struct {
+		Field     string
+		Aggregate string
+		// Name is the label of the value, such as "Sum of amount" by default
+		Name string
+	}
+	// PivotSpec declares a pivot table over the written data by field names
+	PivotSpec struct {
+		Name string
+		// Sheet is the new sheet holding the pivot table, PivotN by default
+		Sheet string
+		// Style is a built-in pivot style such as PivotStyleLight16
+		Style           string
+		Rows            []string
+		Columns         []string
+		Filters         []string
+		Values          []PivotValue
+		HideGrandTotals bool
+	}
+	// GroupSpec writes the aggregates of the data grouped by fields to a new sheet
+	GroupSpec struct {
+		// Sheet is the new sheet holding the groups, GroupN by default
+		Sheet  string
+		By     []string
+		Values []PivotValue
+	}
+)
+
+// pivotAggregates maps the aggregates to the subtotal names of pivot tables
+var pivotAggregates = map[string]string{
+	"sum":       "Sum",
+	"average":   "Average",
+	"count":     "Count",
+	"countNums": "CountNums",
+	"max":       "Max",
+	"min":       "Min",
+	"product":   "Product",
+	"stdDev":    "StdDev",
+	"stdDevp":   "StdDevp",
+	"var":       "Var",
+	"varp":      "Varp",
+}
+
+func (v PivotValue) aggregate() (string, error) {
+	name := v.Aggregate
+	switch {
+	case name == "":
+		return "sum", nil
+	case strings.EqualFold(name, "avg"):
+		return "average", nil
+	}
+	for aggregate := range pivotAggregates {
+		if strings.EqualFold(aggregate, name) {
+			return aggregate, nil
+		}
+	}
+	return "", errors.New("unsupported aggregate: " + name)
+}
+
+func (v PivotValue) label() (string, error) {
+	aggregate, err := v.aggregate()
+	if err != nil || v.Name != "" {
+		return v.Name, err
+	}
+	return pivotAggregates[aggregate] + " of " + v.Field, nil
+}
+
+// addPivots adds the pivot tables on new sheets sourced from the written range
+func addPivots(f *excelize.File, sheet string, header, labels []string, headerRows, dataRows int, o WriteOptions) error {
+	if headerRows > 1 {
+		return errors.New("pivot tables do not support grouped headers")
+	}
+
+	// pivot fields are matched by the header cells, that is the labels
+	label := func(field string) (string, error) {
+		i := slices.Index(header, field)
+		if i < 0 {
+			return "", errors.New("pivot references unknown field: " + field)
+		}
+		return labels[i], nil
+	}
+	fields := func(names []string) ([]excelize.PivotTableField, error) {
+		list := make([]excelize.PivotTableField, 0, len(names))
+		for _, name := range names {
+			l, err := label(name)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, excelize.PivotTableField{Data: l, DefaultSubtotal: true})
+		}
+		return list, nil
+	}
+
+	dataRange := sheet + "!$A$1:$" + ToCol(len(header)-1) + "$" + strconv.Itoa(1+max(dataRows, 1))
+	for n, spec := range o.Pivots {
+		if len(spec.Values) == 0 {
+			return errors.New("pivot has no values")
+		}
+		rows, err := fields(spec.Rows)
+		if err != nil {
+			return err
+		}
+		columns, err := fields(spec.Columns)
+		if err != nil {
+			return err
+		}
+		filters, err := fields(spec.Filters)
+		if err != nil {
+			return err
+		}
+		values := make([]excelize.PivotTableField, 0, len(spec.Values))
+		for _, v := range spec.Values {
+			l, err := label(v.Field)
+			if err != nil {
+				return err
+			}
+			aggregate, _ := v.aggregate()
+			name, err := v.label()
+			if err != nil {
+				return err
+			}
+			values = append(values, excelize.PivotTableField{Data: l, Name: name, Subtotal: pivotAggregates[aggregate]})
+		}
+
+		name, pivotSheet, style := spec.Name, spec.Sheet, spec.Style
+		if name == "" {
+			name = "PivotTable" + strconv.Itoa(n+1)
+		}
+		if pivotSheet == "" {
+			pivotSheet = "Pivot" + strconv.Itoa(n+1)
+		}
+		if style == "" {
+			style = DefaultPivotStyle
+		}
+		if err = CheckSheetName(pivotSheet); err != nil {
+			return err
+		}
+		if _, err = f.NewSheet(pivotSheet); err != nil {
+			return err
+		}
+
+		// the pivot is laid out by Excel when the workbook is opened
+		err = f.AddPivotTable(&excelize.PivotTableOptions{
+			DataRange:           dataRange,
+			PivotTableRange:     pivotSheet + "!$A$3:$" + ToCol(max(len(header), 2)) + "$" + strconv.Itoa(3+max(dataRows, 1)),
+			Name:                name,
+			Rows:                rows,
+			Columns:             columns,
+			Filter:              filters,
+			Data:                values,
+			RowGrandTotals:      !spec.HideGrandTotals,
+			ColGrandTotals:      !spec.HideGrandTotals,
+			ShowDrill:           true,
+			ShowRowHeaders:      true,
+			ShowColHeaders:      true,
+			ShowLastColumn:      true,
+			CompactData:         true,
+			PivotTableStyleName: style,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GroupBy aggregates the rows grouped by the given fields, the groups are
+// sorted field by field on their values (numbers numerically, including
+// numeric text, times chronologically, others as text) and hold the fields
+// and the value labels
+func GroupBy(data ztype.Maps, by []string, values ...PivotValue) (ztype.Maps, error) {
+	type group struct {
+		row    ztype.Map
+		keys   []interface{}
+		values [][]interface{}
+	}
+
+	groups := map[string]*group{}
+	list := make([]*group, 0)
+	for i := range data {
+		parts := make([]string, len(by))
+		for j, field := range by {
+			parts[j] = ztype.ToString(data[i][field])
+		}
+		key := strings.Join(parts, "\x00")
+		g, ok := groups[key]
+		if !ok {
+			g = &group{
+				row:    make(ztype.Map, len(by)+len(values)),
+				keys:   make([]interface{}, len(by)),
+				values: make([][]interface{}, len(values)),
+			}
+			for j, field := range by {
+				g.row[field] = data[i][field]
+				g.keys[j] = data[i][field]
+			}
+			groups[key] = g
+			list = append(list, g)
+		}
+		for j, v := range values {
+			g.values[j] = append(g.values[j], data[i][v.Field])
+		}
+	}
+	slices.SortStableFunc(list, func(a, b *group) int {
+		for j := range by {
+			if c := compareValues(a.keys[j], b.keys[j]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	result := make(ztype.Maps, 0, len(list))
+	for _, g := range list {
+		for j, v := range values {
+			aggregate, err := v.aggregate()
+			if err != nil {
+				return nil, err
+			}
+			name, _ := v.label()
+			g.row[name] = aggregateValues(aggregate, g.values[j])
+		}
+		result = append(result, g.row)
+	}
+	return result, nil
+}
+
+// compareValues orders two values, empty values come first, numbers and
+// numeric text are compared numerically and times chronologically
+func compareValues(a, b interface{}) int {
+	emptyA, emptyB := a == nil || a == "", b == nil || b == ""
+	switch {
+	case emptyA || emptyB:
+		return cmpBool(emptyB, emptyA)
+	}
+
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+	if na, ok := numericValue(a); ok {
+		if nb, ok := numericValue(b); ok {
+			return cmp.Compare(na, nb)
+		}
+	}
+	return strings.Compare(ztype.ToString(a), ztype.ToString(b))
+}
+
+// cmpBool orders false before true
+func cmpBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// numericValue returns the number held by a numeric value or numeric text
+func numericValue(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return n, err == nil
+	case json.Number:
+		n, err := val.Float64()
+		return n, err == nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return ztype.ToFloat64(val), true
+	}
+	return 0, false
+}
+
+// aggregateValues computes the aggregate of the values, count counts the
+// values that are not empty and the others use the numeric values only
+func aggregateValues(aggregate string, values []interface{}) interface{} {
+	nums := make([]float64, 0, len(values))
+	count := 0
+	for _, v := range values {
+		if v == nil || v == "" {
+			continue
+		}
+		count++
+		switch val := v.(type) {
+		case string:
+			if n, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+				nums = append(nums, n)
+			}
+		case bool:
+		default:
+			nums = append(nums, ztype.ToFloat64(val))
+		}
+	}
+
+	switch aggregate {
+	case "count":
+		return count
+	case "countNums":
+		return len(nums)
+	}
+	if len(nums) == 0 {
+		return nil
+	}
+
+	sum, product := 0.0, 1.0
+	minValue, maxValue := nums[0], nums[0]
+	for _, n := range nums {
+		sum += n
+		product *= n
+		minValue, maxValue = min(minValue, n), max(maxValue, n)
+	}
+	mean := sum / float64(len(nums))
+	variance := func(sample bool) interface{} {
+		d := float64(len(nums))
+		if sample {
+			d--
+		}
+		if d <= 0 {
+			return nil
+		}
+		sq := 0.0
+		for _, n := range nums {
+			sq += (n - mean) * (n - mean)
+		}
+		return sq / d
+	}
+
+	switch aggregate {
+	case "average":
+		return mean
+	case "max":
+		return maxValue
+	case "min":
+		return minValue
+	case "product":
+		return product
+	case "var":
+		return variance(true)
+	case "varp":
+		return variance(false)
+	case "stdDev", "stdDevp":
+		v := variance(aggregate == "stdDev")
+		if v == nil {
+			return nil
+		}
+		return math.Sqrt(v.(float64))
+	}
+	return sum
+}
+
+// addGroups writes the aggregates of every group spec to its own sheet
+func addGroups(f *excelize.File, data ztype.Maps, o WriteOptions) error {
+	for n, spec := range o.GroupBy {
+		rows, err := GroupBy(data, spec.By, spec.Values...)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		first := append([]string{}, spec.By...)
+		for _, v := range spec.Values {
+			name, _ := v.label()
+			first = append(first, name)
+		}
+		sheet := spec.Sheet
+		if sheet == "" {
+			sheet = "Group" + strconv.Itoa(n+1)
+		}
+		err = write(f, rows, func(wo *WriteOptions) {
+			wo.Sheet = sheet
+			wo.First = first
+			wo.HeaderStyle = o.HeaderStyle
+			wo.AutoWidth = o.AutoWidth
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
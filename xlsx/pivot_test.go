@@ -0,0 +1,139 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+var pivotData = ztype.Maps{
+	{"region": "north", "product": "a", "amount": 10, "qty": 1},
+	{"region": "south", "product": "b", "amount": 20, "qty": 2},
+	{"region": "north", "product": "b", "amount": 30, "qty": 3},
+	{"region": "south", "product": "a", "amount": 40, "qty": ""},
+}
+
+func TestWritePivots(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	b, err := xlsx.Write(pivotData, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"region", "product", "amount", "qty"}
+		wo.Columns = []xlsx.ColumnSpec{{Field: "amount", Label: "金额"}}
+		wo.Pivots = []xlsx.PivotSpec{
+			{Rows: []string{"region"}, Columns: []string{"product"}, Values: []xlsx.PivotValue{{Field: "amount"}}},
+			{Name: "ByProduct", Sheet: "汇总", Rows: []string{"product"}, Values: []xlsx.PivotValue{{Field: "qty", Aggregate: "avg"}}},
+		}
+	})
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+	tt.Equal([]string{"Sheet1", "Pivot1", "汇总"}, f.GetSheetList())
+	tt.Equal("Sheet1", f.GetSheetName(f.GetActiveSheetIndex()))
+
+	pivots, err := f.GetPivotTables("Pivot1")
+	tt.NoError(err)
+	tt.Equal(1, len(pivots))
+	tt.Equal("Sheet1!A1:D5", pivots[0].DataRange)
+	tt.Equal("PivotTable1", pivots[0].Name)
+	tt.Equal("region", pivots[0].Rows[0].Data)
+	tt.Equal("product", pivots[0].Columns[0].Data)
+	tt.Equal("金额", pivots[0].Data[0].Data)
+	tt.Equal("Sum", pivots[0].Data[0].Subtotal)
+	tt.Equal("Sum of amount", pivots[0].Data[0].Name)
+
+	pivots, err = f.GetPivotTables("汇总")
+	tt.NoError(err)
+	tt.Equal(1, len(pivots))
+	tt.Equal("ByProduct", pivots[0].Name)
+	tt.Equal("Average", pivots[0].Data[0].Subtotal)
+
+	_, err = xlsx.Write(pivotData, func(wo *xlsx.WriteOptions) {
+		wo.Pivots = []xlsx.PivotSpec{{Rows: []string{"missing"}, Values: []xlsx.PivotValue{{Field: "amount"}}}}
+	})
+	tt.EqualTrue(err != nil)
+
+	_, err = xlsx.Write(pivotData, func(wo *xlsx.WriteOptions) {
+		wo.Pivots = []xlsx.PivotSpec{{Rows: []string{"region"}, Values: []xlsx.PivotValue{{Field: "amount", Aggregate: "median"}}}}
+	})
+	tt.EqualTrue(err != nil)
+}
+
+func TestGroupBy(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	rows, err := xlsx.GroupBy(pivotData, []string{"region"},
+		xlsx.PivotValue{Field: "amount"},
+		xlsx.PivotValue{Field: "amount", Aggregate: "avg", Name: "avg"},
+		xlsx.PivotValue{Field: "qty", Aggregate: "count"},
+		xlsx.PivotValue{Field: "amount", Aggregate: "max"},
+	)
+	tt.NoError(err)
+	tt.Equal(2, len(rows))
+	tt.Equal("north", rows[0].Get("region").String())
+	tt.Equal(40.0, rows[0].Get("Sum of amount").Float64())
+	tt.Equal(20.0, rows[0].Get("avg").Float64())
+	tt.Equal(2, rows[0].Get("Count of qty").Int())
+	tt.Equal("south", rows[1].Get("region").String())
+	tt.Equal(60.0, rows[1].Get("Sum of amount").Float64())
+	tt.Equal(1, rows[1].Get("Count of qty").Int())
+	tt.Equal(40.0, rows[1].Get("Max of amount").Float64())
+
+	_, err = xlsx.GroupBy(pivotData, []string{"region"}, xlsx.PivotValue{Field: "amount", Aggregate: "median"})
+	tt.EqualTrue(err != nil)
+
+	// groups are sorted by typed values, not by their text
+	months := ztype.Maps{
+		{"year": "2024", "month": 9, "amount": 1},
+		{"year": "2024", "month": 10, "amount": 2},
+		{"year": "2024", "month": 2, "amount": 3},
+		{"year": "2023", "month": "11", "amount": 4},
+		{"year": "2024", "month": nil, "amount": 5},
+	}
+	rows, err = xlsx.GroupBy(months, []string{"year", "month"}, xlsx.PivotValue{Field: "amount"})
+	tt.NoError(err)
+	got := make([]string, 0, len(rows))
+	for _, row := range rows {
+		got = append(got, row.Get("year").String()+"-"+row.Get("month").String())
+	}
+	tt.Equal([]string{"2023-11", "2024-", "2024-2", "2024-9", "2024-10"}, got)
+}
+
+func TestWriteGroupBy(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	b, err := xlsx.Write(pivotData, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"region", "product", "amount", "qty"}
+		wo.GroupBy = []xlsx.GroupSpec{
+			{Sheet: "按区域", By: []string{"region"}, Values: []xlsx.PivotValue{{Field: "amount"}, {Field: "qty", Aggregate: "sum", Name: "数量"}}},
+			{By: []string{"region", "product"}, Values: []xlsx.PivotValue{{Field: "amount", Aggregate: "count"}}},
+		}
+	})
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+	tt.Equal([]string{"Sheet1", "按区域", "Group2"}, f.GetSheetList())
+	tt.Equal("Sheet1", f.GetSheetName(f.GetActiveSheetIndex()))
+
+	rows, err := f.GetRows("按区域")
+	tt.NoError(err)
+	tt.Equal([][]string{
+		{"region", "Sum of amount", "数量"},
+		{"north", "40", "4"},
+		{"south", "60", "2"},
+	}, rows)
+
+	rows, err = f.GetRows("Group2")
+	tt.NoError(err)
+	tt.Equal(5, len(rows))
+	tt.Equal([]string{"region", "product", "Count of amount"}, rows[0])
+	tt.Equal([]string{"north", "a", "1"}, rows[1])
+	tt.Equal([]string{"south", "b", "1"}, rows[4])
+}
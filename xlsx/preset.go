@@ -0,0 +1,110 @@
+package xlsx
+
+import (
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultZebraColor is the fill of every other data row when ZebraStripes is set
+const DefaultZebraColor = "#F5F7FA"
+
+// applyHeaderPresets styles, freezes and filters the header rows and stripes
+// the data rows below them, cols is the number of written columns
+func applyHeaderPresets(f *excelize.File, sheet string, cols, headerRows, dataRows int, o WriteOptions) error {
+	if cols == 0 || headerRows == 0 {
+		return nil
+	}
+
+	if headerStyle := headerPresetStyle(headerRows, o); headerStyle != nil {
+		style, err := f.NewStyle(headerStyle)
+		if err != nil {
+			return err
+		}
+		if err = f.SetCellStyle(sheet, "A1", ToCol(cols-1)+strconv.Itoa(headerRows), style); err != nil {
+			return err
+		}
+	}
+	if err := freezeHeader(f, sheet, headerRows, o); err != nil {
+		return err
+	}
+	return applyDataPresets(f, sheet, cols, headerRows, dataRows, o)
+}
+
+// headerPresetStyle returns the style of the header rows, nil when the
+// header keeps the style of its columns
+func headerPresetStyle(headerRows int, o WriteOptions) *excelize.Style {
+	headerStyle := o.HeaderStyle
+	if headerRows > 1 {
+		// grouped headers are centered over the columns and rows they span
+		s := excelize.Style{}
+		if headerStyle != nil {
+			s = *headerStyle
+		}
+		if s.Alignment == nil {
+			s.Alignment = &excelize.Alignment{Horizontal: "center", Vertical: "center"}
+		}
+		headerStyle = &s
+	}
+	if o.Protect != nil {
+		// the header is read-only on protected sheets
+		s := excelize.Style{}
+		if headerStyle != nil {
+			s = *headerStyle
+		}
+		headerStyle = protectStyle(&s, true)
+	}
+	return headerStyle
+}
+
+// freezeHeader keeps the header rows visible when FreezeHeader is set
+func freezeHeader(f *excelize.File, sheet string, headerRows int, o WriteOptions) error {
+	if !o.FreezeHeader {
+		return nil
+	}
+	return f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      headerRows,
+		TopLeftCell: "A" + strconv.Itoa(headerRows+1),
+		ActivePane:  "bottomLeft",
+	})
+}
+
+// applyDataPresets filters the header and stripes the data rows, it needs
+// the number of data rows so streams apply it once every row is written
+func applyDataPresets(f *excelize.File, sheet string, cols, headerRows, dataRows int, o WriteOptions) error {
+	lastCol := ToCol(cols - 1)
+	lastRow := headerRows + dataRows
+
+	// tables carry their own filter, a sheet filter over them corrupts the file
+	if o.AutoFilter && o.AsTable == nil {
+		ref := "A" + strconv.Itoa(headerRows) + ":" + lastCol + strconv.Itoa(max(lastRow, headerRows+1))
+		if err := f.AutoFilter(sheet, ref, nil); err != nil {
+			return err
+		}
+	}
+
+	if o.ZebraStripes && dataRows > 1 {
+		color := o.ZebraColor
+		if color == "" {
+			color = DefaultZebraColor
+		}
+		// a conditional format keeps the number formats and styles of the cells
+		style, err := f.NewConditionalStyle(&excelize.Style{
+			Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}},
+		})
+		if err != nil {
+			return err
+		}
+		ref := "A" + strconv.Itoa(headerRows+1) + ":" + lastCol + strconv.Itoa(lastRow)
+		err = f.SetConditionalFormat(sheet, ref, []excelize.ConditionalFormatOptions{{
+			Type:     "formula",
+			Criteria: "MOD(ROW()-" + strconv.Itoa(headerRows) + ",2)=0",
+			Format:   &style,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteHeaderPresets(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data := ztype.Maps{
+		{"id": 1, "name": "a", "price": 1.5},
+		{"id": 2, "name": "b", "price": 2},
+		{"id": 3, "name": "c", "price": 3},
+	}
+	b, err := xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.FreezeHeader = true
+		wo.AutoFilter = true
+		wo.ZebraStripes = true
+		wo.HeaderStyle = &excelize.Style{
+			Font: &excelize.Font{Bold: true},
+			Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#DDEBF7"}},
+		}
+		wo.Columns = []xlsx.ColumnSpec{{Field: "price", NumFmt: "0.00"}}
+	})
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+
+	panes, err := f.GetPanes("Sheet1")
+	tt.NoError(err)
+	tt.EqualTrue(panes.Freeze)
+	tt.Equal(1, panes.YSplit)
+	tt.Equal("A2", panes.TopLeftCell)
+
+	names := f.GetDefinedName()
+	tt.Equal(1, len(names))
+	tt.Equal("_xlnm._FilterDatabase", names[0].Name)
+	tt.Equal("'Sheet1'!$A$1:$C$4", names[0].RefersTo)
+
+	formats, err := f.GetConditionalFormats("Sheet1")
+	tt.NoError(err)
+	tt.Equal(1, len(formats["A2:C4"]))
+	tt.Equal("MOD(ROW()-1,2)=0", formats["A2:C4"][0].Criteria)
+
+	id, _ := f.GetCellStyle("Sheet1", "C1")
+	style, err := f.GetStyle(id)
+	tt.NoError(err)
+	tt.EqualTrue(style.Font.Bold)
+	tt.Equal([]string{"DDEBF7"}, style.Fill.Color)
+
+	rows, err := f.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal([]string{"price", "id", "name"}, rows[0])
+	tt.Equal([]string{"2.00", "2", "b"}, rows[2])
+}
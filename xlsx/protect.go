@@ -0,0 +1,54 @@
+package xlsx
+
+import "github.com/xuri/excelize/v2"
+
+// Protection protects the written sheet, the header and the columns declared
+// Locked are read-only while the other cells stay open for input
+type Protection struct {
+	Password string
+	// AllowFormat allows formatting cells, columns and rows
+	AllowFormat      bool
+	AllowInsertRows  bool
+	AllowDeleteRows  bool
+	AllowSort        bool
+	AllowAutoFilter  bool
+	AllowHyperlinks  bool
+	AllowPivotTables bool
+	// Structure protects the workbook structure with the same password, so
+	// sheets cannot be added, renamed, moved or deleted
+	Structure bool
+}
+
+// protectStyle sets the protection of a column style, nil styles are created
+func protectStyle(style *excelize.Style, locked bool) *excelize.Style {
+	if style == nil {
+		style = &excelize.Style{}
+	}
+	style.Protection = &excelize.Protection{Locked: locked}
+	return style
+}
+
+// protect protects the sheet and the workbook structure as declared
+func protect(f *excelize.File, sheet string, p Protection) error {
+	err := f.ProtectSheet(sheet, &excelize.SheetProtectionOptions{
+		Password:            p.Password,
+		FormatCells:         p.AllowFormat,
+		FormatColumns:       p.AllowFormat,
+		FormatRows:          p.AllowFormat,
+		InsertRows:          p.AllowInsertRows,
+		DeleteRows:          p.AllowDeleteRows,
+		Sort:                p.AllowSort,
+		AutoFilter:          p.AllowAutoFilter,
+		InsertHyperlinks:    p.AllowHyperlinks,
+		PivotTables:         p.AllowPivotTables,
+		SelectLockedCells:   true,
+		SelectUnlockedCells: true,
+	})
+	if err != nil || !p.Structure {
+		return err
+	}
+	return f.ProtectWorkbook(&excelize.WorkbookProtectionOptions{
+		Password:      p.Password,
+		LockStructure: true,
+	})
+}
@@ -0,0 +1,140 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteProtect(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data := ztype.Maps{
+		{"id": 1, "name": "a", "amount": 1.5, "site": xlsx.Link{URL: "https://example.com"}},
+		{"id": 2, "name": "b", "amount": 2.5, "site": nil},
+	}
+	b, err := xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "name", "amount", "site"}
+		wo.Columns = []xlsx.ColumnSpec{
+			{Field: "id", Locked: true},
+			{Field: "amount", NumFmt: "0.00"},
+		}
+		wo.HeaderStyle = &excelize.Style{Font: &excelize.Font{Bold: true}}
+		wo.Protect = &xlsx.Protection{Password: "secret", AllowSort: true, Structure: true}
+	})
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+
+	locked := func(cell string) bool {
+		id, err := f.GetCellStyle("Sheet1", cell)
+		tt.NoError(err)
+		style, err := f.GetStyle(id)
+		tt.NoError(err)
+		return style.Protection == nil || style.Protection.Locked
+	}
+	tt.EqualTrue(locked("A1"))
+	tt.EqualTrue(locked("B1"))
+	tt.EqualTrue(locked("A2"))
+	tt.EqualTrue(!locked("B2"))
+	tt.EqualTrue(!locked("C3"))
+	tt.EqualTrue(!locked("D2"))
+
+	id, err := f.GetCellStyle("Sheet1", "A1")
+	tt.NoError(err)
+	style, err := f.GetStyle(id)
+	tt.NoError(err)
+	tt.EqualTrue(style.Font != nil && style.Font.Bold)
+	id, err = f.GetCellStyle("Sheet1", "C2")
+	tt.NoError(err)
+	style, err = f.GetStyle(id)
+	tt.NoError(err)
+	tt.Equal("0.00", *style.CustomNumFmt)
+
+	// rows below the data stay open for input
+	id, err = f.GetColStyle("Sheet1", "B")
+	tt.NoError(err)
+	style, err = f.GetStyle(id)
+	tt.NoError(err)
+	tt.EqualTrue(style.Protection != nil && !style.Protection.Locked)
+
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	tt.NoError(err)
+	parts := map[string]string{}
+	for _, file := range z.File {
+		r, err := file.Open()
+		tt.NoError(err)
+		c, _ := io.ReadAll(r)
+		r.Close()
+		parts[file.Name] = string(c)
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	tt.EqualTrue(strings.Contains(sheet, "<sheetProtection"))
+	tt.EqualTrue(strings.Contains(sheet, `password="`))
+	tt.EqualTrue(strings.Contains(sheet, `sort="false"`))
+	tt.EqualTrue(strings.Contains(sheet, `formatCells="true"`))
+	tt.EqualTrue(strings.Contains(parts["xl/workbook.xml"], `lockStructure="true"`))
+
+	// header links written by CellHandlerV2 stay locked on unlocked columns
+	b, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "name", "amount", "site"}
+		wo.Protect = &xlsx.Protection{}
+		wo.CellHandlerV2 = func(ctx xlsx.CellContext) (xlsx.CellResult, error) {
+			if ctx.Header && ctx.Field == "name" {
+				return xlsx.CellResult{Link: &xlsx.Link{Text: "name", URL: "#Sheet1!B2"}}, nil
+			}
+			return xlsx.CellResult{}, nil
+		}
+	})
+	tt.NoError(err)
+	f2, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f2.Close()
+	id, err = f2.GetCellStyle("Sheet1", "B1")
+	tt.NoError(err)
+	style, err = f2.GetStyle(id)
+	tt.NoError(err)
+	tt.EqualTrue(style.Protection != nil && style.Protection.Locked)
+	tt.Equal(strings.TrimPrefix(xlsx.DefaultLinkColor, "#"), style.Font.Color)
+	id, err = f2.GetCellStyle("Sheet1", "D2")
+	tt.NoError(err)
+	style, err = f2.GetStyle(id)
+	tt.NoError(err)
+	tt.EqualTrue(style.Protection != nil && !style.Protection.Locked)
+
+	// styles of the cell handlers keep the protection of the cells
+	x, err := xlsx.Open("")
+	tt.NoError(err)
+	defer x.Close()
+	red, err := x.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "#FF0000"}})
+	tt.NoError(err)
+	b, err = x.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "name", "amount", "site"}
+		wo.Columns = []xlsx.ColumnSpec{{Field: "id", Locked: true}}
+		wo.Protect = &xlsx.Protection{}
+		wo.CellHandlerV2 = func(ctx xlsx.CellContext) (xlsx.CellResult, error) {
+			return xlsx.CellResult{Style: red}, nil
+		}
+	})
+	tt.NoError(err)
+	f3, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f3.Close()
+	for cell, locked := range map[string]bool{"A1": true, "B1": true, "A2": true, "B2": false, "C3": false} {
+		id, err = f3.GetCellStyle("Sheet1", cell)
+		tt.NoError(err)
+		style, err = f3.GetStyle(id)
+		tt.NoError(err)
+		tt.Equal("FF0000", style.Font.Color)
+		tt.Equal(locked, style.Protection == nil || style.Protection.Locked)
+	}
+}
@@ -0,0 +1,502 @@
+package xlsx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	// blockPattern matches the cells marking row blocks: range, if, else and end
+	blockPattern = regexp.MustCompile(`^\{\{-?\s*(range|if|else|end)\b\s*(.*?)\s*-?\}\}$`)
+	// actionPattern matches cells holding a single action, whose value keeps its type
+	actionPattern = regexp.MustCompile(`^\{\{-?\s*([^{}]+?)\s*-?\}\}$`)
+	// refPattern matches the cell references and ranges of formulas
+	refPattern = regexp.MustCompile(`(\$?[A-Z]{1,3})(\$?)(\d+)(?::(\$?[A-Z]{1,3})(\$?)(\d+))?`)
+)
+
+type renderer struct {
+	f     *excelize.File
+	data  interface{}
+	sheet string
+	// rows are the template rows, first and last the output rows of each,
+	// 0 once removed, shift the rows added above the row being rendered
+	rows        [][]string
+	first, last []int
+	shift       int
+}
+
+// cellFormula is a formula of the template kept aside while rendering
+type cellFormula struct {
+	col     string
+	formula string
+	row     int
+}
+
+// Render fills the placeholders of every sheet with data using text/template
+// syntax, such as {{ .customer.name }}. Rows between {{range .items}} and
+// {{end}} marker rows are copied with their styles for every item, where . is
+// the item, $ is data and loop is the index of the item. Rows between
+// {{if .cond}}, {{else}} and {{end}} are kept or removed, and formula ranges
+// over a repeated block grow with it, or become 0 when it has no items. Cells
+// holding a single placeholder keep the type of the value.
+func (x *Xlsx) Render(data interface{}) error {
+	for _, sheet := range x.f.GetSheetList() {
+		r := &renderer{f: x.f, data: data, sheet: sheet}
+		if err := r.render(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blockMarker returns the block keyword and expression of a marker row
+func blockMarker(row []string) (string, string) {
+	for _, v := range row {
+		if m := blockPattern.FindStringSubmatch(strings.TrimSpace(v)); m != nil {
+			return m[1], m[2]
+		}
+	}
+	return "", ""
+}
+
+// matchBlock returns the else and end marker rows of the block starting at row
+func matchBlock(rows [][]string, row int) (int, int, error) {
+	depth, elseRow := 0, -1
+	for i := row + 1; i < len(rows); i++ {
+		switch kind, _ := blockMarker(rows[i]); kind {
+		case "range", "if":
+			depth++
+		case "else":
+			if depth == 0 {
+				elseRow = i
+			}
+		case "end":
+			if depth == 0 {
+				return elseRow, i, nil
+			}
+			depth--
+		}
+	}
+	return -1, -1, errors.New("missing {{end}} of the block at row " + strconv.Itoa(row+1))
+}
+
+func (r *renderer) render() error {
+	rows, err := r.f.GetRows(r.sheet)
+	if err != nil {
+		return err
+	}
+	r.rows, r.first, r.last = rows, make([]int, len(rows)), make([]int, len(rows))
+
+	formulas, err := r.takeFormulas()
+	if err != nil {
+		return err
+	}
+	if err = r.renderRows(0, len(rows)); err != nil {
+		return err
+	}
+	return r.putFormulas(formulas)
+}
+
+// row returns the current row number of the template row t
+func (r *renderer) row(t int) int {
+	return t + 1 + r.shift
+}
+
+// renderRows renders the template rows from first to last, exclusive, top
+// down so the rows above are final and only shift the rows below
+func (r *renderer) renderRows(first, last int) error {
+	for t := first; t < last; {
+		kind, expr := blockMarker(r.rows[t])
+		switch kind {
+		case "":
+			row := r.row(t)
+			if err := r.renderRow(row, r.rows[t], r.data, -1); err != nil {
+				return err
+			}
+			r.first[t], r.last[t] = row, row
+			t++
+		case "range":
+			elseRow, end, err := matchBlock(r.rows, t)
+			if err != nil {
+				return err
+			}
+			if elseRow >= 0 {
+				return fmt.Errorf("%s: {{else}} of range is not supported at row %d", r.sheet, elseRow+1)
+			}
+			if err = r.renderRange(t, end, expr); err != nil {
+				return err
+			}
+			t = end + 1
+		case "if":
+			elseRow, end, err := matchBlock(r.rows, t)
+			if err != nil {
+				return err
+			}
+			if err = r.renderIf(t, elseRow, end, expr); err != nil {
+				return err
+			}
+			t = end + 1
+		default:
+			return fmt.Errorf("%s: unexpected {{%s}} at row %d", r.sheet, kind, t+1)
+		}
+	}
+	return nil
+}
+
+// removeRows removes the template rows from first to last, inclusive
+func (r *renderer) removeRows(first, last int) error {
+	for t := last; t >= first; t-- {
+		if err := r.f.RemoveRow(r.sheet, r.row(t)); err != nil {
+			return err
+		}
+	}
+	r.shift -= last - first + 1
+	return nil
+}
+
+// renderIf keeps the rows of the branch chosen by the condition and removes
+// the marker rows and the other branch
+func (r *renderer) renderIf(start, elseRow, end int, expr string) error {
+	ok, err := r.truthy(expr)
+	if err != nil {
+		return fmt.Errorf("%s!A%d: %w", r.sheet, start+1, err)
+	}
+
+	switch {
+	case ok:
+		bodyEnd := end
+		if elseRow >= 0 {
+			bodyEnd = elseRow
+		}
+		if err = r.removeRows(start, start); err != nil {
+			return err
+		}
+		if err = r.renderRows(start+1, bodyEnd); err != nil {
+			return err
+		}
+		return r.removeRows(bodyEnd, end)
+	case elseRow >= 0:
+		if err = r.removeRows(start, elseRow); err != nil {
+			return err
+		}
+		if err = r.renderRows(elseRow+1, end); err != nil {
+			return err
+		}
+		return r.removeRows(end, end)
+	}
+	return r.removeRows(start, end)
+}
+
+// renderRange copies the body rows of the block for every item and fills them
+func (r *renderer) renderRange(start, end int, expr string) error {
+	for t := start + 1; t < end; t++ {
+		if kind, _ := blockMarker(r.rows[t]); kind != "" {
+			return fmt.Errorf("%s: blocks nested in range are not supported at row %d", r.sheet, t+1)
+		}
+	}
+
+	items, err := r.items(expr)
+	if err != nil {
+		return fmt.Errorf("%s!A%d: %w", r.sheet, start+1, err)
+	}
+
+	if err = r.removeRows(start, start); err != nil {
+		return err
+	}
+	height := end - start - 1
+	if height == 0 || len(items) == 0 {
+		return r.removeRows(start+1, end)
+	}
+
+	first := r.row(start + 1)
+	for k := 1; k < len(items); k++ {
+		for t := 0; t < height; t++ {
+			if err = r.f.DuplicateRowTo(r.sheet, first+t, first+k*height+t); err != nil {
+				return err
+			}
+		}
+	}
+	r.shift += (len(items) - 1) * height
+
+	for t := 0; t < height; t++ {
+		r.first[start+1+t], r.last[start+1+t] = first+t, first+(len(items)-1)*height+t
+	}
+	for k, item := range items {
+		for t := 0; t < height; t++ {
+			if err = r.renderRow(first+k*height+t, r.rows[start+1+t], item, k); err != nil {
+				return err
+			}
+		}
+	}
+	return r.removeRows(end, end)
+}
+
+// takeFormulas clears the formulas outside the range bodies and returns them,
+// so adding and removing rows leaves them alone until putFormulas rewrites
+// them once. Formulas within the bodies are copied with the rows.
+func (r *renderer) takeFormulas() ([]cellFormula, error) {
+	body, cols := make([]bool, len(r.rows)), 0
+	for t := range r.rows {
+		cols = max(cols, len(r.rows[t]))
+		if kind, _ := blockMarker(r.rows[t]); kind == "range" {
+			if _, end, err := matchBlock(r.rows, t); err == nil {
+				for i := t + 1; i < end; i++ {
+					body[i] = true
+				}
+			}
+		}
+	}
+
+	formulas := []cellFormula{}
+	for t := range r.rows {
+		if body[t] {
+			continue
+		}
+		for j := 0; j < cols; j++ {
+			cell := ToCell(t, j)
+			formula, err := r.f.GetCellFormula(r.sheet, cell)
+			if err != nil {
+				return nil, err
+			}
+			if formula == "" {
+				continue
+			}
+			if err = r.f.SetCellFormula(r.sheet, cell, ""); err != nil {
+				return nil, err
+			}
+			formulas = append(formulas, cellFormula{col: ToCol(j), formula: formula, row: t})
+		}
+	}
+	return formulas, nil
+}
+
+// putFormulas writes the formulas taken before rendering back to the rows
+// they moved to, with their references following the rendered rows
+func (r *renderer) putFormulas(formulas []cellFormula) error {
+	for _, c := range formulas {
+		if r.first[c.row] == 0 {
+			continue
+		}
+		cell := c.col + strconv.Itoa(r.first[c.row])
+		if err := r.f.SetCellFormula(r.sheet, cell, r.rewriteRefs(c.formula)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteRefs moves the references of a formula to the rendered rows. A range
+// spans from the first copy of its first row to the last copy of its last
+// row, shrinks to the rows left when the ends are removed, and becomes 0
+// when none is left. A reference to a removed cell becomes #REF!.
+func (r *renderer) rewriteRefs(formula string) string {
+	var b strings.Builder
+	prev := 0
+	for _, m := range refPattern.FindAllStringSubmatchIndex(formula, -1) {
+		if !isRef(formula, m[0], m[1]) {
+			continue
+		}
+		b.WriteString(formula[prev:m[0]])
+		prev = m[1]
+
+		from, _ := strconv.Atoi(formula[m[6]:m[7]])
+		if m[8] < 0 {
+			row := r.firstRow(from, false)
+			if row == 0 {
+				b.WriteString("#REF!")
+				continue
+			}
+			b.WriteString(formula[m[2]:m[6]] + strconv.Itoa(row))
+			continue
+		}
+
+		to, _ := strconv.Atoi(formula[m[12]:m[13]])
+		first, last := r.firstRow(from, true), r.lastRow(to)
+		if first > last {
+			b.WriteString("0")
+			continue
+		}
+		b.WriteString(formula[m[2]:m[6]] + strconv.Itoa(first) + ":" + formula[m[8]:m[12]] + strconv.Itoa(last))
+	}
+	b.WriteString(formula[prev:])
+	return b.String()
+}
+
+// firstRow returns the first output row of the template row n, 1-based,
+// or of the next row left when next is set, 0 when it was removed
+func (r *renderer) firstRow(n int, next bool) int {
+	for t := n - 1; t < len(r.rows); t++ {
+		if r.first[t] != 0 || !next {
+			return r.first[t]
+		}
+	}
+	return max(n, len(r.rows)+1) + r.shift
+}
+
+// lastRow returns the last output row of the template row n, 1-based, or of
+// the previous row left, 0 when there is none
+func (r *renderer) lastRow(n int) int {
+	if n > len(r.rows) {
+		return n + r.shift
+	}
+	for t := n - 1; t >= 0; t-- {
+		if r.last[t] != 0 {
+			return r.last[t]
+		}
+	}
+	return 0
+}
+
+// isRef reports whether the match from i to j is a cell reference of the
+// sheet rather than part of a name, a function, a string or a reference to
+// another sheet
+func isRef(formula string, i, j int) bool {
+	if strings.Count(formula[:i], `"`)%2 == 1 {
+		return false
+	}
+	if i > 0 && strings.ContainsRune("!$_.:'", rune(formula[i-1])) || i > 0 && isWordByte(formula[i-1]) {
+		return false
+	}
+	return j == len(formula) || formula[j] != '(' && formula[j] != '!' && !isWordByte(formula[j])
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_'
+}
+
+// renderRow fills the placeholders of the cells of a row, index is the
+// position of the range item or -1 outside ranges
+func (r *renderer) renderRow(row int, cells []string, dot interface{}, index int) error {
+	for j, text := range cells {
+		if !strings.Contains(text, "{{") {
+			continue
+		}
+		cell := ToCol(j) + strconv.Itoa(row)
+
+		if m := actionPattern.FindStringSubmatch(strings.TrimSpace(text)); m != nil && !blockPattern.MatchString(strings.TrimSpace(text)) {
+			v, err := r.value(m[1], dot, index)
+			if err != nil {
+				return fmt.Errorf("%s!%s: %w", r.sheet, cell, err)
+			}
+			if err = r.f.SetCellValue(r.sheet, cell, v); err != nil {
+				return err
+			}
+			continue
+		}
+
+		out, err := r.execute(text, dot, index, nil)
+		if err != nil {
+			return fmt.Errorf("%s!%s: %w", r.sheet, cell, err)
+		}
+		if err = r.f.SetCellStr(r.sheet, cell, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute runs the template text with dot as ., data as $ and loop as the
+// index of the range item
+func (r *renderer) execute(text string, dot interface{}, index int, capture func(interface{}) string) (string, error) {
+	funcs := template.FuncMap{
+		"loop":   func() int { return index },
+		"__item": func() []interface{} { return []interface{}{dot} },
+		"__text": func(v interface{}) interface{} {
+			if v == nil {
+				return ""
+			}
+			return v
+		},
+	}
+	if capture != nil {
+		funcs["__capture"] = capture
+	}
+	if index >= 0 {
+		// ranging over the single item keeps $ bound to the data
+		text = "{{range __item}}" + text + "{{end}}"
+	}
+
+	t, err := template.New(r.sheet).Funcs(funcs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	emptyMissing(t.Tree, t.Root)
+	var b strings.Builder
+	if err = t.Execute(&b, r.data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// emptyMissing pipes the output of every action to __text, so that missing
+// keys print nothing instead of <no value>
+func emptyMissing(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			emptyMissing(tree, c)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier("__text").SetTree(tree).SetPos(n.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		emptyMissing(tree, n.List)
+		emptyMissing(tree, n.ElseList)
+	case *parse.RangeNode:
+		emptyMissing(tree, n.List)
+		emptyMissing(tree, n.ElseList)
+	case *parse.WithNode:
+		emptyMissing(tree, n.List)
+		emptyMissing(tree, n.ElseList)
+	}
+}
+
+// value evaluates the pipeline and returns its value
+func (r *renderer) value(expr string, dot interface{}, index int) (interface{}, error) {
+	var v interface{}
+	_, err := r.execute("{{__capture ("+expr+")}}", dot, index, func(x interface{}) string {
+		v = x
+		return ""
+	})
+	return v, err
+}
+
+// truthy evaluates the condition of an if block with the template rules
+func (r *renderer) truthy(expr string) (bool, error) {
+	out, err := r.execute("{{if "+expr+"}}1{{end}}", r.data, -1, nil)
+	return out == "1", err
+}
+
+// items evaluates the pipeline of a range block to its items
+func (r *renderer) items(expr string) ([]interface{}, error) {
+	v, err := r.value(expr, r.data, -1)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, errors.New("range value is not a slice: " + expr)
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, nil
+}
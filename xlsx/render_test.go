@@ -0,0 +1,158 @@
+package xlsx_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func renderTemplate(t *testing.T, path string) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	cells := map[string]interface{}{
+		"A1": "报价单 {{ .customer.name }}",
+		"A2": "品名", "B2": "单价", "C2": "数量", "D2": "小计",
+		"A3": "{{range .items}}",
+		"A4": "{{ .name }}", "B4": "{{ .price }}", "C4": "{{ .qty }}", "E4": "{{ $.customer.name }}-{{ loop }}",
+		"A5":  "{{end}}",
+		"A6":  "合计",
+		"A7":  "{{ if .vip }}",
+		"A8":  "VIP {{ .customer.name }}",
+		"A9":  "{{else}}",
+		"A10": "普通客户",
+		"A11": "{{end}}",
+		"A12": "{{ .note }}",
+		"A13": `{{ printf "%d 项" (len .items) }}`,
+	}
+	for cell, v := range cells {
+		if err := f.SetCellValue("Sheet1", cell, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = f.SetCellFormula("Sheet1", "D4", "B4*C4")
+	_ = f.SetCellFormula("Sheet1", "C6", "SUM(C4:C4)")
+	_ = f.SetCellFormula("Sheet1", "D6", "SUM(D4:D4)")
+	style, _ := f.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#FFEEAA"}}})
+	_ = f.SetCellStyle("Sheet1", "A4", "D4", style)
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_render.xlsx"
+	defer os.Remove(testFile)
+	renderTemplate(t, testFile)
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+
+	tt.NoError(f.Render(ztype.Map{
+		"customer": ztype.Map{"name": "ACME"},
+		"vip":      false,
+		"items": ztype.Maps{
+			{"name": "a", "price": 12.5, "qty": 2},
+			{"name": "b", "price": 3, "qty": 4},
+			{"name": "c", "price": 1, "qty": 1},
+		},
+	}))
+	e := f.Engine()
+
+	rows, err := e.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal(9, len(rows))
+	tt.Equal("报价单 ACME", rows[0][0])
+	tt.Equal([]string{"a", "12.5", "2", "", "ACME-0"}, rows[2])
+	tt.Equal([]string{"c", "1", "1", "", "ACME-2"}, rows[4])
+	tt.Equal("合计", rows[5][0])
+	tt.Equal("普通客户", rows[6][0])
+	tt.Equal(0, len(rows[7]))
+	tt.Equal("3 项", rows[8][0])
+
+	typ, _ := e.GetCellType("Sheet1", "B3")
+	tt.Equal(excelize.CellTypeUnset, typ)
+
+	for cell, want := range map[string]string{"D3": "B3*C3", "D5": "B5*C5", "C6": "SUM(C3:C5)", "D6": "SUM(D3:D5)"} {
+		formula, err := e.GetCellFormula("Sheet1", cell)
+		tt.NoError(err)
+		tt.Equal(want, formula)
+	}
+	total, err := e.CalcCellValue("Sheet1", "D6")
+	tt.NoError(err)
+	tt.Equal("38", total)
+
+	id, _ := e.GetCellStyle("Sheet1", "A5")
+	s, err := e.GetStyle(id)
+	tt.NoError(err)
+	tt.Equal([]string{"FFEEAA"}, s.Fill.Color)
+
+	// missing keys print nothing, values are kept as they are
+	x, err := xlsx.Open("")
+	tt.NoError(err)
+	defer x.Close()
+	tt.NoError(x.Engine().SetCellValue("Sheet1", "A1", "备注：{{ .remark }}{{ .missing }}"))
+	tt.NoError(x.Render(ztype.Map{"remark": "<no value>"}))
+	tt.Equal("备注：<no value>", x.Get("Sheet1", "A1").String())
+}
+
+func TestRenderBlocks(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_render_blocks.xlsx"
+	defer os.Remove(testFile)
+	renderTemplate(t, testFile)
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+	tt.NoError(f.Engine().SetCellFormula("Sheet1", "E6", "COUNTA(A2:A4)"))
+
+	tt.NoError(f.Render(map[string]interface{}{
+		"customer": map[string]string{"name": "ACME"},
+		"vip":      true,
+		"items":    []ztype.Map{},
+	}))
+	rows, err := f.Engine().GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal("合计", rows[2][0])
+	tt.Equal("VIP ACME", rows[3][0])
+	tt.Equal("0 项", rows[5][0])
+
+	// ranges over the removed body do not shift onto the rows below
+	for cell, want := range map[string]string{"C3": "SUM(0)", "D3": "SUM(0)", "E3": "COUNTA(A2:A2)"} {
+		formula, err := f.Engine().GetCellFormula("Sheet1", cell)
+		tt.NoError(err)
+		tt.Equal(want, formula)
+	}
+
+	// ranges nested in if blocks, formulas are rewritten once after rendering
+	x, err := xlsx.Open("")
+	tt.NoError(err)
+	defer x.Close()
+	e := x.Engine()
+	for cell, v := range map[string]string{"A1": "{{if .show}}", "A2": "{{range .items}}", "A3": "{{ . }}", "A4": "{{end}}", "A5": "{{end}}"} {
+		tt.NoError(e.SetCellValue("Sheet1", cell, v))
+	}
+	tt.NoError(e.SetCellFormula("Sheet1", "B6", `COUNTA(A3:A3)&"A3"&LOG10(A3)`))
+	tt.NoError(x.Render(ztype.Map{"show": true, "items": []string{"x", "y"}}))
+	rows, err = e.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal("y", rows[1][0])
+	formula, err := e.GetCellFormula("Sheet1", "B3")
+	tt.NoError(err)
+	tt.Equal(`COUNTA(A1:A2)&"A3"&LOG10(A1)`, formula)
+
+	f2, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f2.Close()
+	_ = f2.Engine().SetCellValue("Sheet1", "A20", "{{range .items}}")
+	tt.EqualTrue(f2.Render(ztype.Map{"items": ztype.Maps{}}) != nil)
+}
@@ -0,0 +1,221 @@
+package xlsx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztype"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// sniffSize is the amount of text inspected to detect the encoding and the
+// delimiter of streamed csv files
+const sniffSize = 64 << 10
+
+// rowIterator yields the rows of a source one at a time, next returns
+// io.EOF after the last row
+type rowIterator struct {
+	next   func() (ztype.Map, error)
+	close  func() error
+	header []string
+}
+
+// openRows returns the rows of a file of the given format as they are read.
+// csv, tsv, json and ndjson files are streamed, spreadsheets and reads
+// needing every row (Reverse, Handler, NoHeaderRow) are loaded first
+func openRows(path, format string, o ReadOptions) (*rowIterator, error) {
+	switch {
+	case (format == FormatCSV || format == FormatTSV) && !o.Reverse && o.Handler == nil && !o.NoHeaderRow:
+		if format == FormatTSV && o.Delimiter == 0 {
+			o.Delimiter = '\t'
+		}
+		f, err := os.Open(zfile.RealPath(path))
+		if err != nil {
+			return nil, err
+		}
+		rows, err := csvRows(f, o)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		rows.close = f.Close
+		return rows, nil
+	case format == FormatJSON || format == FormatNDJSON:
+		return jsonRows(path)
+	}
+
+	header, data, err := Load(path, format, func(ro *ReadOptions) { *ro = o })
+	if err != nil {
+		return nil, err
+	}
+	i := 0
+	return &rowIterator{
+		header: header,
+		next: func() (ztype.Map, error) {
+			if i == len(data) {
+				return nil, io.EOF
+			}
+			i++
+			return data[i-1], nil
+		},
+		close: func() error { return nil },
+	}, nil
+}
+
+// csvRows streams the rows of csv data as readCSV maps them, empty rows are
+// held back so that trailing ones are dropped
+func csvRows(r io.Reader, o ReadOptions) (*rowIterator, error) {
+	br := bufio.NewReaderSize(decodeTextReader(r), sniffSize)
+	if o.Delimiter == 0 {
+		b, _ := br.Peek(sniffSize)
+		o.Delimiter = detectDelimiter(b)
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comma = o.Delimiter
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	read := func() ([]string, error) {
+		record, err := cr.Read()
+		n := len(record)
+		for n > 0 && record[n-1] == "" {
+			n--
+		}
+		return record[:n], err
+	}
+
+	var headerRow []string
+	for i := 0; i <= o.OffsetY; i++ {
+		row, err := read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		headerRow = row
+	}
+
+	ho := o
+	ho.OffsetY = 0
+	cols, _, _ := parseHeader([][]string{headerRow}, ho)
+	value := func(meta rowMeta, j int, _ string) string {
+		return meta.row[o.OffsetX+j]
+	}
+
+	var (
+		queued  []string
+		empty   int
+		emitted int
+	)
+	next := func() (ztype.Map, error) {
+		for {
+			if o.MaxRows > 0 && emitted >= o.MaxRows {
+				return nil, io.EOF
+			}
+
+			var row []string
+			switch {
+			case empty > 0 && queued != nil:
+				empty--
+			case queued != nil:
+				row, queued = queued, nil
+			default:
+				record, err := read()
+				if err != nil {
+					return nil, err
+				}
+				if len(record) == 0 {
+					empty++
+					continue
+				}
+				if empty > 0 {
+					queued = record
+					continue
+				}
+				row = record
+			}
+
+			emitted++
+			data, isEmpty := mapRow(rowMeta{row: row}, cols, o, value)
+			if !isEmpty {
+				return data, nil
+			}
+			if !o.RemoveEmptyRow {
+				return ztype.Map{}, nil
+			}
+		}
+	}
+	return &rowIterator{header: columnKeys(cols, nil, o), next: next}, nil
+}
+
+// jsonRows streams the objects of a json or ndjson file, the file is read
+// twice so that the header holds the keys of every object
+func jsonRows(path string) (*rowIterator, error) {
+	f, err := os.Open(zfile.RealPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		header []string
+		seen   = make(map[string]struct{})
+		dec    = newJSONRowDecoder(bufio.NewReader(f))
+	)
+	for {
+		_, keys, err := dec.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		for _, key := range keys {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				header = append(header, key)
+			}
+		}
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	dec = newJSONRowDecoder(bufio.NewReader(f))
+	return &rowIterator{
+		header: header,
+		next: func() (ztype.Map, error) {
+			row, _, err := dec.next()
+			return row, err
+		},
+		close: f.Close,
+	}, nil
+}
+
+// decodeTextReader strips the UTF-8 BOM and converts GBK/GB18030 content to
+// UTF-8, the encoding is detected from the beginning of the text
+func decodeTextReader(r io.Reader) io.Reader {
+	br := bufio.NewReaderSize(r, sniffSize)
+	b, _ := br.Peek(sniffSize)
+	if bytes.HasPrefix(b, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+		b = b[len(utf8BOM):]
+	}
+
+	// the sniffed text may end in the middle of a character
+	for i := 0; i < utf8.UTFMax-1 && len(b) > 0 && !utf8.Valid(b); i++ {
+		b = b[:len(b)-1]
+	}
+	if utf8.Valid(b) {
+		return br
+	}
+	return transform.NewReader(br, simplifiedchinese.GB18030.NewDecoder())
+}
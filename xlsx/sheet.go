@@ -0,0 +1,331 @@
+package xlsx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type SheetVisibility string
+
+const (
+	SheetVisible    SheetVisibility = "visible"
+	SheetHidden     SheetVisibility = "hidden"
+	SheetVeryHidden SheetVisibility = "veryHidden"
+)
+
+// MaxSheetNameLength is the maximum number of characters in a sheet name
+const MaxSheetNameLength = 31
+
+type SheetInfo struct {
+	Name      string          `json:"name"`
+	Visible   SheetVisibility `json:"visible"`
+	Dimension string          `json:"dimension"`
+	Index     int             `json:"index"`
+}
+
+// CheckSheetName validates a sheet name against the Excel naming rules
+func CheckSheetName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("sheet name cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > MaxSheetNameLength {
+		return fmt.Errorf("sheet name %q exceeds %d characters", name, MaxSheetNameLength)
+	}
+	if i := strings.IndexAny(name, `:\/?*[]`); i >= 0 {
+		return fmt.Errorf("sheet name %q contains forbidden character %q", name, name[i])
+	}
+	if strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'") {
+		return fmt.Errorf("sheet name %q cannot start or end with an apostrophe", name)
+	}
+	if strings.EqualFold(name, "History") {
+		return fmt.Errorf("sheet name %q is reserved by Excel", name)
+	}
+	return nil
+}
+
+// Sheets returns the sheets of the workbook in tab order
+func (x *Xlsx) Sheets() []SheetInfo {
+	names := x.f.GetSheetList()
+	sheets := make([]SheetInfo, 0, len(names))
+	for i, name := range names {
+		sheets = append(sheets, SheetInfo{
+			Name:      name,
+			Index:     i,
+			Visible:   x.sheetVisibility(name),
+			Dimension: x.sheetDimension(name),
+		})
+	}
+	return sheets
+}
+
+// RenameSheet renames a sheet
+func (x *Xlsx) RenameSheet(name, newName string) error {
+	if _, err := x.sheetIndex(name); err != nil {
+		return err
+	}
+	if err := CheckSheetName(newName); err != nil {
+		return err
+	}
+	if !strings.EqualFold(name, newName) {
+		if _, err := x.sheetIndex(newName); err == nil {
+			return fmt.Errorf("sheet %q already exists", newName)
+		}
+	}
+	return x.f.SetSheetName(name, newName)
+}
+
+// CopySheet copies a sheet to a new sheet, optionally into another workbook,
+// keeping values, formulas, styles, merged cells, column widths and row heights
+func (x *Xlsx) CopySheet(name, newName string, dst ...*Xlsx) error {
+	from, err := x.sheetIndex(name)
+	if err != nil {
+		return err
+	}
+	if err = CheckSheetName(newName); err != nil {
+		return err
+	}
+
+	target := x
+	if len(dst) > 0 && dst[0] != nil {
+		target = dst[0]
+	}
+	if _, err = target.sheetIndex(newName); err == nil {
+		return fmt.Errorf("sheet %q already exists", newName)
+	}
+
+	to, err := target.f.NewSheet(newName)
+	if err != nil {
+		return err
+	}
+	if target == x {
+		err = x.f.CopySheet(from, to)
+	} else {
+		err = copySheetTo(x.f, name, target.f, newName)
+	}
+	if err != nil {
+		// a half copied sheet is not left behind
+		_ = target.f.DeleteSheet(newName)
+	}
+	return err
+}
+
+// DeleteSheet deletes a sheet, the last remaining sheet cannot be deleted
+func (x *Xlsx) DeleteSheet(name string) error {
+	if _, err := x.sheetIndex(name); err != nil {
+		return err
+	}
+	if x.f.SheetCount <= 1 {
+		return fmt.Errorf("cannot delete %q, a workbook must contain at least one sheet", name)
+	}
+	return x.f.DeleteSheet(name)
+}
+
+// MoveSheet moves a sheet to the given 0-based tab position
+func (x *Xlsx) MoveSheet(name string, index int) error {
+	from, err := x.sheetIndex(name)
+	if err != nil {
+		return err
+	}
+
+	names := x.f.GetSheetList()
+	if index < 0 || index >= len(names) {
+		return fmt.Errorf("sheet index %d out of range", index)
+	}
+
+	switch {
+	case index == from:
+		return nil
+	case index < from:
+		return x.f.MoveSheet(name, names[index])
+	case index+1 < len(names):
+		return x.f.MoveSheet(name, names[index+1])
+	}
+
+	for _, s := range names[from+1:] {
+		if err = x.f.MoveSheet(s, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetSheetVisible changes the visibility of a sheet, at least one sheet must stay visible
+func (x *Xlsx) SetSheetVisible(name string, visibility SheetVisibility) error {
+	index, err := x.sheetIndex(name)
+	if err != nil {
+		return err
+	}
+
+	switch visibility {
+	case SheetVisible, "":
+		return x.f.SetSheetVisible(name, true)
+	case SheetHidden, SheetVeryHidden:
+	default:
+		return fmt.Errorf("unknown sheet visibility %q", visibility)
+	}
+
+	if x.f.GetActiveSheetIndex() == index {
+		active := -1
+		for _, s := range x.Sheets() {
+			if s.Index != index && s.Visible == SheetVisible {
+				active = s.Index
+				break
+			}
+		}
+		if active < 0 {
+			return errors.New("a workbook must contain at least one visible sheet")
+		}
+		x.f.SetActiveSheet(active)
+	}
+
+	return x.f.SetSheetVisible(name, false, visibility == SheetVeryHidden)
+}
+
+func (x *Xlsx) sheetIndex(name string) (int, error) {
+	index, err := x.f.GetSheetIndex(name)
+	if err != nil {
+		return -1, err
+	}
+	if index < 0 {
+		return -1, fmt.Errorf("sheet %q does not exist", name)
+	}
+	return index, nil
+}
+
+func (x *Xlsx) sheetVisibility(name string) SheetVisibility {
+	if visible, err := x.f.GetSheetVisible(name); err != nil || visible {
+		return SheetVisible
+	}
+
+	if x.f.WorkBook != nil {
+		for _, s := range x.f.WorkBook.Sheets.Sheet {
+			if s.Name == name && s.State == string(SheetVeryHidden) {
+				return SheetVeryHidden
+			}
+		}
+	}
+	return SheetHidden
+}
+
+func (x *Xlsx) sheetDimension(name string) string {
+	endRow, endCol := -1, -1
+	rows, err := x.f.GetRows(name, excelize.Options{RawCellValue: true})
+	if err == nil {
+		for i := range rows {
+			if len(rows[i]) > 0 {
+				endRow = i
+			}
+			if len(rows[i])-1 > endCol {
+				endCol = len(rows[i]) - 1
+			}
+		}
+	}
+
+	// the stored dimension may be stale until saved, so only use it to widen the range
+	if ref, err := x.f.GetSheetDimension(name); err == nil && ref != "" {
+		if _, _, r, c, err := parseRange(ref); err == nil {
+			endRow, endCol = max(endRow, r), max(endCol, c)
+		}
+	}
+
+	if endRow < 0 || endCol < 0 {
+		return ""
+	}
+	return "A1:" + ToCell(endRow, endCol)
+}
+
+func copySheetTo(src *excelize.File, sheet string, dst *excelize.File, newSheet string) error {
+	from := &Xlsx{f: src}
+	ref := from.sheetDimension(sheet)
+	if ref == "" {
+		return nil
+	}
+
+	startRow, startCol, endRow, endCol, err := parseRange(ref)
+	if err != nil {
+		return err
+	}
+
+	// rows keep the default height of the sheet, only the others are copied
+	props, err := src.GetSheetProps(sheet)
+	if err != nil {
+		return err
+	}
+	defaultHeight := 15.0
+	if props.CustomHeight != nil && *props.CustomHeight && props.DefaultRowHeight != nil {
+		defaultHeight = *props.DefaultRowHeight
+		err = dst.SetSheetProps(newSheet, &excelize.SheetPropsOptions{
+			CustomHeight:     props.CustomHeight,
+			DefaultRowHeight: props.DefaultRowHeight,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	styles := make(map[int]int)
+	for r := startRow; r <= endRow; r++ {
+		for c := startCol; c <= endCol; c++ {
+			cell := ToCell(r, c)
+			if formula, _ := src.GetCellFormula(sheet, cell); formula != "" {
+				err = dst.SetCellFormula(newSheet, cell, formula)
+			} else if value := from.cellValue(sheet, cell); value != nil {
+				err = dst.SetCellValue(newSheet, cell, value)
+			}
+			if err != nil {
+				return err
+			}
+
+			styleID, _ := src.GetCellStyle(sheet, cell)
+			if styleID == 0 {
+				continue
+			}
+			id, ok := styles[styleID]
+			if !ok {
+				style, err := src.GetStyle(styleID)
+				if err != nil {
+					return err
+				}
+				if id, err = dst.NewStyle(style); err != nil {
+					return err
+				}
+				styles[styleID] = id
+			}
+			if err = dst.SetCellStyle(newSheet, cell, cell, id); err != nil {
+				return err
+			}
+		}
+
+		height, err := src.GetRowHeight(sheet, r+1)
+		if err != nil {
+			return err
+		}
+		if height != defaultHeight {
+			if err = dst.SetRowHeight(newSheet, r+1, height); err != nil {
+				return err
+			}
+		}
+	}
+
+	for c := startCol; c <= endCol; c++ {
+		col := ToCol(c)
+		if width, err := src.GetColWidth(sheet, col); err == nil {
+			_ = dst.SetColWidth(newSheet, col, col, width)
+		}
+	}
+
+	merges, err := src.GetMergeCells(sheet)
+	if err != nil {
+		return err
+	}
+	for _, m := range merges {
+		if err = dst.MergeCell(newSheet, m.GetStartAxis(), m.GetEndAxis()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
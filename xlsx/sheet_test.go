@@ -0,0 +1,128 @@
+package xlsx_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestSheets(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	f, err := xlsx.Open("")
+	tt.NoError(err)
+	defer f.Close()
+
+	tt.NoError(f.Set("Sheet1", "A1", "name"))
+	tt.NoError(f.Set("Sheet1", "B3", 1))
+	for _, name := range []string{"Second", "Third"} {
+		_, err = f.Engine().NewSheet(name)
+		tt.NoError(err)
+	}
+
+	sheets := f.Sheets()
+	tt.Equal(3, len(sheets))
+	tt.Equal("Sheet1", sheets[0].Name)
+	tt.Equal("A1:B3", sheets[0].Dimension)
+	tt.Equal(xlsx.SheetVisible, sheets[0].Visible)
+
+	tt.NoError(f.RenameSheet("Second", "Data"))
+	tt.EqualTrue(f.RenameSheet("Data", "Third") != nil)
+	tt.EqualTrue(f.RenameSheet("NotExist", "Other") != nil)
+	tt.EqualTrue(f.RenameSheet("Data", "a/b") != nil)
+	tt.EqualTrue(f.RenameSheet("Data", strings.Repeat("x", 32)) != nil)
+
+	tt.NoError(f.MoveSheet("Sheet1", 2))
+	tt.Equal([]string{"Data", "Third", "Sheet1"}, f.Engine().GetSheetList())
+	tt.NoError(f.MoveSheet("Sheet1", 0))
+	tt.NoError(f.MoveSheet("Data", 1))
+	tt.Equal([]string{"Sheet1", "Data", "Third"}, f.Engine().GetSheetList())
+	tt.EqualTrue(f.MoveSheet("Data", 3) != nil)
+
+	tt.NoError(f.SetSheetVisible("Data", xlsx.SheetHidden))
+	tt.NoError(f.SetSheetVisible("Third", xlsx.SheetVeryHidden))
+	sheets = f.Sheets()
+	tt.Equal(xlsx.SheetHidden, sheets[1].Visible)
+	tt.Equal(xlsx.SheetVeryHidden, sheets[2].Visible)
+	tt.EqualTrue(f.SetSheetVisible("Sheet1", xlsx.SheetHidden) != nil)
+	tt.NoError(f.SetSheetVisible("Data", xlsx.SheetVisible))
+	tt.NoError(f.SetSheetVisible("Sheet1", xlsx.SheetHidden))
+	tt.Equal("Data", f.Engine().GetSheetName(f.Engine().GetActiveSheetIndex()))
+
+	tt.NoError(f.DeleteSheet("Third"))
+	tt.NoError(f.DeleteSheet("Sheet1"))
+	tt.EqualTrue(f.DeleteSheet("Data") != nil)
+	tt.EqualTrue(f.DeleteSheet("NotExist") != nil)
+}
+
+func TestCopySheet(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	src, err := xlsx.Open("")
+	tt.NoError(err)
+	defer src.Close()
+
+	style, err := src.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	tt.NoError(err)
+	tt.NoError(src.Set("Sheet1", "A1", "title"))
+	tt.NoError(src.Set("Sheet1", "A2", 10))
+	tt.NoError(src.SetFormula("Sheet1", "B2", "A2*2"))
+	tt.NoError(src.Engine().SetCellStyle("Sheet1", "A1", "A1", style))
+	tt.NoError(src.Engine().MergeCell("Sheet1", "A1", "B1"))
+	tt.NoError(src.Engine().SetColWidth("Sheet1", "A", "A", 30))
+	tt.NoError(src.Engine().SetRowHeight("Sheet1", 2, 24))
+
+	tt.NoError(src.CopySheet("Sheet1", "Copy"))
+	tt.Equal(10, src.Get("Copy", "A2").Int())
+	tt.EqualTrue(src.CopySheet("Sheet1", "Copy") != nil)
+
+	dst, err := xlsx.Open("")
+	tt.NoError(err)
+	defer dst.Close()
+
+	tt.NoError(src.CopySheet("Sheet1", "Other", dst))
+	tt.Equal("title", dst.Get("Other", "A1").String())
+	tt.Equal(10, dst.Get("Other", "A2").Int())
+
+	formula, err := dst.Engine().GetCellFormula("Other", "B2")
+	tt.NoError(err)
+	tt.Equal("A2*2", formula)
+
+	styleID, err := dst.Engine().GetCellStyle("Other", "A1")
+	tt.NoError(err)
+	s, err := dst.Engine().GetStyle(styleID)
+	tt.NoError(err)
+	tt.EqualTrue(s.Font != nil && s.Font.Bold)
+
+	merges, err := dst.Engine().GetMergeCells("Other")
+	tt.NoError(err)
+	tt.Equal(1, len(merges))
+	tt.Equal("A1", merges[0].GetStartAxis())
+
+	width, err := dst.Engine().GetColWidth("Other", "A")
+	tt.NoError(err)
+	tt.Equal(30.0, width)
+
+	height, err := dst.Engine().GetRowHeight("Other", 2)
+	tt.NoError(err)
+	tt.Equal(24.0, height)
+}
+
+func TestCheckSheetName(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	tt.NoError(xlsx.CheckSheetName("数据 2025"))
+	tt.NoError(xlsx.CheckSheetName(strings.Repeat("表", 31)))
+	for _, name := range []string{"", " ", strings.Repeat("表", 32), "a:b", "a[1]", "'name", "history"} {
+		tt.EqualTrue(xlsx.CheckSheetName(name) != nil)
+	}
+
+	_, err := xlsx.Write(ztype.Maps{{"a": 1}}, func(wo *xlsx.WriteOptions) {
+		wo.Sheet = "a/b"
+	})
+	tt.EqualTrue(err != nil)
+}
@@ -0,0 +1,221 @@
+package xlsx
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/sohaha/zlsgo/zarray"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/sohaha/zlsgo/zutil"
+	"github.com/xuri/excelize/v2"
+)
+
+// streamOptions are the WriteOptions fields applied by StreamWriter, the
+// others need the whole data or random access to the written cells
+var streamOptions = []string{
+	"Sheet", "First", "Last", "Columns", "NilValue", "DateFormats", "DateFormat",
+	"DateTimeFormat", "StringifyLargeInts", "HeaderStyle", "HeaderSeparator",
+	"ZebraColor", "FreezeHeader", "Validations", "ConditionalFormats", "Protect",
+	"AutoFilter", "ZebraStripes",
+}
+
+// StreamWriter writes rows to a sheet one at a time without keeping them in
+// memory, the header presets are applied as with Write
+type StreamWriter struct {
+	f          *excelize.File
+	sw         *excelize.StreamWriter
+	styles     *numFmtStyles
+	header     []string
+	cells      []interface{}
+	colStyles  []int
+	o          WriteOptions
+	headerRows int
+	rows       int
+	flushed    bool
+}
+
+// NewStreamWriter starts streaming rows with the header to a new sheet,
+// the header is ordered by First (or Columns) and Last, options that need
+// the whole data such as AutoWidth, Formulas or AsTable are rejected
+func (x *Xlsx) NewStreamWriter(header []string, opt ...func(*WriteOptions)) (*StreamWriter, error) {
+	if len(header) == 0 {
+		return nil, errors.New("no header")
+	}
+
+	o := zutil.Optional(WriteOptions{
+		Sheet:          "Sheet1",
+		DateFormat:     DefaultDateFormat,
+		DateTimeFormat: DefaultDateTimeFormat,
+	}, opt...)
+	if err := checkStreamOptions(o); err != nil {
+		return nil, err
+	}
+
+	header = zarray.SortWithPriority(header, columnFields(o), o.Last)
+	index, err := x.f.NewSheet(o.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	x.f.SetActiveSheet(index)
+
+	// column styles and panes must be set before the stream writes any row
+	s := &StreamWriter{f: x.f, styles: newNumFmtStyles(x.f), header: header, o: o}
+	if err = applyColumns(x.f, o.Sheet, header, o, s.styles); err != nil {
+		return nil, err
+	}
+
+	labels := headerLabels(header, o)
+	grid := headerGrid(labels, o.HeaderSeparator)
+	s.headerRows = len(grid)
+	if err = freezeHeader(x.f, o.Sheet, s.headerRows, o); err != nil {
+		return nil, err
+	}
+	if s.sw, err = x.f.NewStreamWriter(o.Sheet); err != nil {
+		return nil, err
+	}
+
+	// the stream only looks up the style of the first column of a row, so
+	// every cell carries the style of its column
+	s.colStyles = make([]int, len(header))
+	for i := range header {
+		if s.colStyles[i], err = x.f.GetColStyle(o.Sheet, ToCol(i)); err != nil {
+			return nil, err
+		}
+	}
+
+	var merges [][2]string
+	if s.headerRows > 1 {
+		// merged cells only keep the value of their top left cell
+		merges = headerMerges(labels, o.HeaderSeparator, s.headerRows)
+		for _, m := range merges {
+			startCol, startRow, _ := excelize.CellNameToCoordinates(m[0])
+			endCol, endRow, _ := excelize.CellNameToCoordinates(m[1])
+			for r := startRow - 1; r < endRow; r++ {
+				for c := startCol - 1; c < endCol; c++ {
+					if r != startRow-1 || c != startCol-1 {
+						grid[r][c] = ""
+					}
+				}
+			}
+		}
+	}
+
+	headerStyle := 0
+	if style := headerPresetStyle(s.headerRows, o); style != nil {
+		if headerStyle, err = x.f.NewStyle(style); err != nil {
+			return nil, err
+		}
+	}
+	for r := range grid {
+		cells := make([]interface{}, len(grid[r]))
+		for i := range grid[r] {
+			cell := excelize.Cell{StyleID: headerStyle}
+			if headerStyle == 0 {
+				cell.StyleID = s.colStyles[i]
+			}
+			if grid[r][i] != "" {
+				cell.Value = grid[r][i]
+			}
+			cells[i] = cell
+		}
+		if err = s.sw.SetRow("A"+strconv.Itoa(r+1), cells); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range merges {
+		if err = s.sw.MergeCell(m[0], m[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = applyValidations(x.f, o.Sheet, header, s.headerRows, o); err != nil {
+		return nil, err
+	}
+	s.cells = make([]interface{}, len(header))
+	return s, nil
+}
+
+// checkStreamOptions rejects the options that StreamWriter cannot apply
+func checkStreamOptions(o WriteOptions) error {
+	if err := checkWriteOptions("stream", o, streamOptions); err != nil {
+		return err
+	}
+	for _, c := range o.Columns {
+		if c.Hidden {
+			return errors.New("stream output does not support ColumnSpec.Hidden")
+		}
+	}
+	return nil
+}
+
+// Header returns the fields of the written columns in order
+func (s *StreamWriter) Header() []string {
+	return s.header
+}
+
+// Write writes the values of the row below the previous one, fields that
+// are not in the header are ignored
+func (s *StreamWriter) Write(row ztype.Map) error {
+	if s.flushed {
+		return errors.New("stream is flushed")
+	}
+
+	r := s.headerRows + s.rows + 1
+	for i := range s.header {
+		v := writeValue(s.header[i], row[s.header[i]], &s.o)
+		cell := excelize.Cell{StyleID: s.colStyles[i], Value: v.value}
+		if v.numeric != "" {
+			// streams have no raw numbers, digits beyond float64 precision are rounded
+			n, err := strconv.ParseFloat(v.numeric, 64)
+			if err != nil {
+				return err
+			}
+			cell.Value = n
+		}
+		if v.link != nil {
+			if err := addLink(s.f, s.o.Sheet, ToCell(r-1, i), *v.link); err != nil {
+				return err
+			}
+			style, err := s.styles.link(i, false)
+			if err != nil {
+				return err
+			}
+			cell.StyleID = style
+		} else if v.numFmt != "" && !s.styles.fixed[i] {
+			style, err := s.styles.get(i, v.numFmt)
+			if err != nil {
+				return err
+			}
+			cell.StyleID = style
+		}
+		s.cells[i] = cell
+	}
+	if err := s.sw.SetRow("A"+strconv.Itoa(r), s.cells); err != nil {
+		return err
+	}
+	s.rows++
+	return nil
+}
+
+// Flush applies the presets that span the data rows and ends the stream,
+// the workbook can be saved afterwards
+func (s *StreamWriter) Flush() error {
+	if s.flushed {
+		return nil
+	}
+	s.flushed = true
+
+	sheet, cols := s.o.Sheet, len(s.header)
+	if err := applyDataPresets(s.f, sheet, cols, s.headerRows, s.rows, s.o); err != nil {
+		return err
+	}
+	if err := applyConditionalFormats(s.f, sheet, s.header, s.headerRows, s.rows, s.o); err != nil {
+		return err
+	}
+	if s.o.Protect != nil {
+		if err := protect(s.f, sheet, *s.o.Protect); err != nil {
+			return err
+		}
+	}
+	return s.sw.Flush()
+}
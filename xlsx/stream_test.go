@@ -0,0 +1,103 @@
+package xlsx_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestStreamWriter(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_stream.xlsx"
+	defer os.Remove(testFile)
+
+	x, err := xlsx.Open("")
+	tt.NoError(err)
+	defer x.Close()
+
+	s, err := x.NewStreamWriter([]string{"name", "2024.Q1", "2024.Q2", "id", "day", "site"}, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"id", "name"}
+		wo.HeaderSeparator = "."
+		wo.FreezeHeader = true
+		wo.AutoFilter = true
+		wo.ZebraStripes = true
+		wo.HeaderStyle = &excelize.Style{Font: &excelize.Font{Bold: true}}
+		wo.Columns = []xlsx.ColumnSpec{{Field: "2024.Q1", NumFmt: "0.00", Width: 20}}
+		wo.ConditionalFormats = map[string][]xlsx.ConditionalFormat{
+			"2024.Q2": {{Type: "dataBar"}},
+		}
+	})
+	tt.NoError(err)
+	tt.Equal([]string{"id", "name", "2024.Q1", "2024.Q2", "day", "site"}, s.Header())
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 3; i++ {
+		tt.NoError(s.Write(ztype.Map{
+			"id": i, "name": "n", "2024.Q1": 1.5, "2024.Q2": json.Number("12345.678"),
+			"day": day, "site": xlsx.Link{URL: "https://example.com"},
+		}))
+	}
+	tt.NoError(s.Flush())
+	tt.EqualTrue(s.Write(ztype.Map{}) != nil)
+	tt.NoError(x.SaveAs(testFile))
+
+	f, err := excelize.OpenFile(testFile)
+	tt.NoError(err)
+	defer f.Close()
+
+	rows, err := f.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal(5, len(rows))
+	tt.Equal([]string{"id", "name", "2024", "", "day", "site"}, rows[0])
+	tt.Equal([]string{"", "", "Q1", "Q2"}, rows[1])
+	tt.Equal([]string{"2", "n", "1.50", "12345.678", "2024-03-01", "https://example.com"}, rows[3])
+
+	merges, err := f.GetMergeCells("Sheet1")
+	tt.NoError(err)
+	tt.Equal(5, len(merges))
+
+	panes, err := f.GetPanes("Sheet1")
+	tt.NoError(err)
+	tt.EqualTrue(panes.Freeze)
+	tt.Equal(2, panes.YSplit)
+
+	names := f.GetDefinedName()
+	tt.Equal(1, len(names))
+	tt.Equal("'Sheet1'!$A$2:$F$5", names[0].RefersTo)
+
+	formats, err := f.GetConditionalFormats("Sheet1")
+	tt.NoError(err)
+	tt.Equal("MOD(ROW()-2,2)=0", formats["A3:F5"][0].Criteria)
+	tt.Equal(1, len(formats["D3:D5"]))
+
+	id, _ := f.GetCellStyle("Sheet1", "C1")
+	style, err := f.GetStyle(id)
+	tt.NoError(err)
+	tt.EqualTrue(style.Font.Bold)
+	tt.Equal("center", style.Alignment.Horizontal)
+
+	width, err := f.GetColWidth("Sheet1", "C")
+	tt.NoError(err)
+	tt.Equal(20.0, width)
+
+	link, target, err := f.GetCellHyperLink("Sheet1", "F4")
+	tt.NoError(err)
+	tt.EqualTrue(link)
+	tt.Equal("https://example.com", target)
+
+	for _, opt := range []func(*xlsx.WriteOptions){
+		func(wo *xlsx.WriteOptions) { wo.AutoWidth = true },
+		func(wo *xlsx.WriteOptions) { wo.AsTable = &xlsx.TableOptions{} },
+		func(wo *xlsx.WriteOptions) { wo.Columns = []xlsx.ColumnSpec{{Field: "id", Hidden: true}} },
+	} {
+		_, err = x.NewStreamWriter([]string{"id"}, opt)
+		tt.EqualTrue(err != nil)
+	}
+}
@@ -0,0 +1,275 @@
+package xlsx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/sohaha/zlsgo/zutil"
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultTableStyle is the style of tables written with AsTable
+const DefaultTableStyle = "TableStyleMedium2"
+
+// TableOptions writes the data as an Excel table (ListObject)
+type TableOptions struct {
+	// Totals maps fields to the aggregate of the totals row:
+	// sum, average (avg), count, countNums, min, max, stdDev or var
+	Totals map[string]string
+	Name   string
+	// Style is a built-in table style such as TableStyleMedium2
+	Style string
+	// TotalsLabel is written in the first column of the totals row, "Total" by default
+	TotalsLabel       string
+	ShowColumnStripes bool
+	HideRowStripes    bool
+}
+
+// totalsFunctions maps the totals row functions to their SUBTOTAL codes
+var totalsFunctions = map[string]int{
+	"average":   101,
+	"count":     103,
+	"countNums": 102,
+	"max":       104,
+	"min":       105,
+	"stdDev":    107,
+	"sum":       109,
+	"var":       110,
+}
+
+type tablePart struct {
+	HeaderRowCount *int   `xml:"headerRowCount,attr"`
+	Name           string `xml:"name,attr"`
+	Ref            string `xml:"ref,attr"`
+	TotalsRowCount int    `xml:"totalsRowCount,attr"`
+}
+
+type (
+	// tableXML is a table part as far as the totals row needs it, the other
+	// attributes and elements of the part are written back as they are read
+	tableXML struct {
+		XMLName        xml.Name        `xml:"table"`
+		XMLNS          string          `xml:"xmlns,attr"`
+		Ref            string          `xml:"ref,attr"`
+		TotalsRowCount int             `xml:"totalsRowCount,attr,omitempty"`
+		TotalsRowShown *bool           `xml:"totalsRowShown,attr"`
+		Attrs          []xml.Attr      `xml:",any,attr"`
+		AutoFilter     *tableXMLInner  `xml:"autoFilter"`
+		SortState      *tableXMLInner  `xml:"sortState"`
+		TableColumns   tableXMLColumns `xml:"tableColumns"`
+		TableStyleInfo *tableXMLInner  `xml:"tableStyleInfo"`
+		ExtLst         *tableXMLInner  `xml:"extLst"`
+	}
+	tableXMLColumns struct {
+		Count   int              `xml:"count,attr"`
+		Columns []tableXMLColumn `xml:"tableColumn"`
+	}
+	tableXMLColumn struct {
+		ID                int        `xml:"id,attr"`
+		Name              string     `xml:"name,attr"`
+		TotalsRowFunction string     `xml:"totalsRowFunction,attr,omitempty"`
+		TotalsRowLabel    string     `xml:"totalsRowLabel,attr,omitempty"`
+		Attrs             []xml.Attr `xml:",any,attr"`
+		Inner             string     `xml:",innerxml"`
+	}
+	tableXMLInner struct {
+		Attrs []xml.Attr `xml:",any,attr"`
+		Inner string     `xml:",innerxml"`
+	}
+)
+
+func totalsFunction(name string) (string, int, error) {
+	if strings.EqualFold(name, "avg") {
+		name = "average"
+	}
+	for fn, code := range totalsFunctions {
+		if strings.EqualFold(fn, name) {
+			return fn, code, nil
+		}
+	}
+	return "", 0, errors.New("unsupported totals function: " + name)
+}
+
+// structuredRef escapes a column name for a structured reference
+func structuredRef(name string) string {
+	return strings.NewReplacer("'", "''", "[", "'[", "]", "']", "#", "'#").Replace(name)
+}
+
+// addTable registers a table over the header and data rows, and adds a
+// totals row to it when totals are declared
+func addTable(f *excelize.File, sheet string, header, labels []string, headerRow, dataRows int, t TableOptions) error {
+	if len(header) == 0 {
+		return nil
+	}
+
+	name := t.Name
+	if name == "" {
+		name = "Table1"
+	}
+	style := t.Style
+	if style == "" {
+		style = DefaultTableStyle
+	}
+	stripes := !t.HideRowStripes
+
+	lastCol := ToCol(len(header) - 1)
+	lastRow := headerRow + max(dataRows, 1)
+	err := f.AddTable(sheet, &excelize.Table{
+		Range:             "A" + strconv.Itoa(headerRow) + ":" + lastCol + strconv.Itoa(lastRow),
+		Name:              name,
+		StyleName:         style,
+		ShowColumnStripes: t.ShowColumnStripes,
+		ShowRowStripes:    &stripes,
+	})
+	if err != nil || len(t.Totals) == 0 {
+		return err
+	}
+
+	label := t.TotalsLabel
+	if label == "" {
+		label = "Total"
+	}
+
+	totalsRow := lastRow + 1
+	functions := make(map[int]string, len(t.Totals))
+	for i := range header {
+		cell := ToCol(i) + strconv.Itoa(totalsRow)
+		fn, ok := t.Totals[header[i]]
+		if !ok {
+			if i == 0 {
+				if err = f.SetCellStr(sheet, cell, label); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		fn, code, err := totalsFunction(fn)
+		if err != nil {
+			return err
+		}
+		functions[i] = fn
+		formula := "SUBTOTAL(" + strconv.Itoa(code) + "," + name + "[" + structuredRef(labels[i]) + "])"
+		if err = f.SetCellFormula(sheet, cell, formula); err != nil {
+			return err
+		}
+	}
+
+	// excelize has no totals row option, the part it wrote is extended over
+	// the totals row, the filter keeps covering the data rows only
+	path, _, ok := tablePartName(f, name)
+	if !ok {
+		return fmt.Errorf("table %q does not exist", name)
+	}
+	b, _ := f.Pkg.Load(path)
+	var part tableXML
+	if err = xml.Unmarshal(b.([]byte), &part); err != nil {
+		return err
+	}
+	part.Ref = "A" + strconv.Itoa(headerRow) + ":" + lastCol + strconv.Itoa(totalsRow)
+	part.TotalsRowCount, part.TotalsRowShown = 1, nil
+	for i := range part.TableColumns.Columns {
+		if fn, ok := functions[i]; ok {
+			part.TableColumns.Columns[i].TotalsRowFunction = fn
+		} else if i == 0 {
+			part.TableColumns.Columns[i].TotalsRowLabel = label
+		}
+	}
+	out, err := xml.Marshal(part)
+	if err != nil {
+		return err
+	}
+	f.Pkg.Store(path, append([]byte(xml.Header), out...))
+	return nil
+}
+
+// tablePartName returns the package path and the part of the named table
+func tablePartName(f *excelize.File, name string) (string, tablePart, bool) {
+	var (
+		path string
+		part tablePart
+	)
+	f.Pkg.Range(func(key, value interface{}) bool {
+		k, _ := key.(string)
+		b, ok := value.([]byte)
+		if !ok || !strings.HasPrefix(k, "xl/tables/") {
+			return true
+		}
+		var p tablePart
+		if err := xml.NewDecoder(bytes.NewReader(b)).Decode(&p); err == nil && strings.EqualFold(p.Name, name) {
+			path, part = k, p
+			return false
+		}
+		return true
+	})
+	return path, part, path != ""
+}
+
+// findTable returns the sheet and the part of the named table
+func (x *Xlsx) findTable(name string) (string, tablePart, error) {
+	_, part, ok := tablePartName(x.f, name)
+	if ok {
+		for _, sheet := range x.f.GetSheetList() {
+			tables, err := x.f.GetTables(sheet)
+			if err != nil {
+				return "", part, err
+			}
+			for _, t := range tables {
+				if strings.EqualFold(t.Name, part.Name) {
+					return sheet, part, nil
+				}
+			}
+		}
+	}
+	return "", part, fmt.Errorf("table %q does not exist", name)
+}
+
+// Tables returns the names of the tables of every sheet
+func (x *Xlsx) Tables() []string {
+	names := []string{}
+	for _, sheet := range x.f.GetSheetList() {
+		tables, _ := x.f.GetTables(sheet)
+		for _, t := range tables {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// ReadTable reads the rows of a table by name, the totals row is skipped
+func (x *Xlsx) ReadTable(name string, opt ...func(*ReadOptions)) (ztype.Maps, error) {
+	sheet, part, err := x.findTable(name)
+	if err != nil {
+		return nil, err
+	}
+
+	startRow, startCol, endRow, endCol, err := parseRange(part.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	o := zutil.Optional(ReadOptions{}, opt...)
+	o.Sheet = sheet
+	o.OffsetX, o.OffsetY = startCol, startRow
+	o.NoHeaderRow = part.HeaderRowCount != nil && *part.HeaderRowCount == 0
+	o.lastRow, o.lastCol = endRow+1-part.TotalsRowCount, endCol+1
+
+	_, data, err := x.read(o)
+	return data, err
+}
+
+// ReadTable reads the rows of a table by name from a file
+func ReadTable(path, name string, opt ...func(*ReadOptions)) (ztype.Maps, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return f.ReadTable(name, opt...)
+}
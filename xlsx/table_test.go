@@ -0,0 +1,132 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteTable(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_table.xlsx"
+	defer os.Remove(testFile)
+
+	data := ztype.Maps{
+		{"item": "a", "qty": 2, "amount": 10.5},
+		{"item": "b", "qty": 4, "amount": 20},
+	}
+	tt.NoError(xlsx.WriteFile(testFile, data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"item", "qty"}
+		wo.AutoFilter = true
+		wo.Columns = []xlsx.ColumnSpec{{Field: "amount", Label: "金额[元]"}}
+		wo.AsTable = &xlsx.TableOptions{
+			Name:        "Orders",
+			Style:       "TableStyleLight9",
+			Totals:      map[string]string{"amount": "sum", "qty": "avg"},
+			TotalsLabel: "合计",
+		}
+	}))
+
+	r, err := zip.OpenReader(testFile)
+	tt.NoError(err)
+	for _, file := range r.File {
+		if !strings.HasPrefix(file.Name, "xl/tables/") {
+			continue
+		}
+		rc, _ := file.Open()
+		b, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		part := string(b)
+		tt.EqualTrue(strings.Contains(part, `TableStyleLight9`))
+
+		var table struct {
+			Ref            string `xml:"ref,attr"`
+			TotalsRowCount int    `xml:"totalsRowCount,attr"`
+			AutoFilter     struct {
+				Ref string `xml:"ref,attr"`
+			} `xml:"autoFilter"`
+			Columns []struct {
+				Name              string `xml:"name,attr"`
+				TotalsRowFunction string `xml:"totalsRowFunction,attr"`
+				TotalsRowLabel    string `xml:"totalsRowLabel,attr"`
+			} `xml:"tableColumns>tableColumn"`
+		}
+		tt.NoError(xml.Unmarshal(b, &table))
+		tt.Equal("A1:C4", table.Ref)
+		tt.Equal(1, table.TotalsRowCount)
+		tt.Equal("A1:C3", table.AutoFilter.Ref)
+		tt.Equal(3, len(table.Columns))
+		tt.Equal("合计", table.Columns[0].TotalsRowLabel)
+		tt.Equal("average", table.Columns[1].TotalsRowFunction)
+		tt.Equal("金额[元]", table.Columns[2].Name)
+		tt.Equal("sum", table.Columns[2].TotalsRowFunction)
+	}
+	_ = r.Close()
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+
+	tt.Equal([]string{"Orders"}, f.Tables())
+	tt.Equal(0, len(f.Engine().GetDefinedName()))
+
+	formula, err := f.Engine().GetCellFormula("Sheet1", "C4")
+	tt.NoError(err)
+	tt.Equal("SUBTOTAL(109,Orders[金额'[元']])", formula)
+	formula, _ = f.Engine().GetCellFormula("Sheet1", "B4")
+	tt.Equal("SUBTOTAL(101,Orders[qty])", formula)
+	tt.Equal("合计", f.Get("Sheet1", "A4").String())
+
+	rows, err := f.ReadTable("orders")
+	tt.NoError(err)
+	tt.Equal(2, len(rows))
+	tt.Equal("b", rows[1].Get("item").String())
+	tt.Equal(20, rows[1].Get("金额[元]").Int())
+
+	_, err = f.ReadTable("Missing")
+	tt.EqualTrue(err != nil)
+
+	err = xlsx.WriteFile(testFile, data, func(wo *xlsx.WriteOptions) {
+		wo.AsTable = &xlsx.TableOptions{Totals: map[string]string{"qty": "median"}}
+	})
+	tt.EqualTrue(err != nil)
+}
+
+func TestReadTable(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_read_table.xlsx"
+	defer os.Remove(testFile)
+
+	f := excelize.NewFile()
+	rows := [][]interface{}{
+		{"title", nil, nil, nil},
+		{nil, nil, nil, nil},
+		{nil, "name", "age", "note"},
+		{nil, "a", 1, "x"},
+		{nil, "b", 2, "y"},
+		{nil, "c", 3, nil},
+	}
+	for i := range rows {
+		tt.NoError(f.SetSheetRow("Sheet1", "A"+string(rune('1'+i)), &rows[i]))
+	}
+	tt.NoError(f.AddTable("Sheet1", &excelize.Table{Range: "B3:C5", Name: "People"}))
+	tt.NoError(f.SaveAs(testFile))
+	_ = f.Close()
+
+	data, err := xlsx.ReadTable(testFile, "People")
+	tt.NoError(err)
+	tt.Equal(2, len(data))
+	tt.Equal(2, len(data[0]))
+	tt.Equal("a", data[0].Get("name").String())
+	tt.Equal(2, data[1].Get("age").Int())
+}
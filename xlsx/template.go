@@ -0,0 +1,614 @@
+package xlsx
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztime"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+)
+
+// Defaults of import templates
+const (
+	DefaultRequiredMarker    = "*"
+	DefaultInstructionsSheet = "Instructions"
+)
+
+type (
+	// TemplateField declares a column of an import template
+	TemplateField struct {
+		// Example is the value of the field in the example row
+		Example interface{}
+		// Validation replaces the dropdown built from Enum
+		Validation *Validation
+		Field      string
+		Label      string
+		// Description is the comment of the header cell
+		Description string
+		// Type is one of the ColumnType constants, string by default
+		Type string
+		// Format is the number format of the column, derived from Type by default
+		Format   string
+		Enum     []string
+		Width    float64
+		Required bool
+	}
+	// Schema declares an import template and reads the filled in file back
+	Schema struct {
+		// Sheet is the data sheet, Sheet1 by default
+		Sheet string
+		// InstructionsSheet lists the fields, DefaultInstructionsSheet by default
+		InstructionsSheet string
+		// RequiredMarker prefixes the labels of required fields
+		RequiredMarker string
+		// Password protects the header of the data sheet and the instructions
+		Password string
+		Fields   []TemplateField
+		// Examples are more example rows after the one built from the fields
+		Examples ztype.Maps
+		// Notes are written below the field list of the instructions sheet
+		Notes []string
+	}
+)
+
+// ParseSchema builds a schema from the exported fields of a struct, tagged as
+//
+//	Name string `xlsx:"name,required,type=date,format=yyyy/mm/dd,width=20" label:"姓名" desc:"..." enum:"a,b" example:"..."`
+//
+// the key defaults to the json name or the field name, and "-" skips a field
+func ParseSchema(v interface{}) (Schema, error) {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return Schema{}, errors.New("schema must be a struct")
+	}
+
+	s := Schema{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("xlsx")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		field := TemplateField{
+			Field:       parts[0],
+			Label:       sf.Tag.Get("label"),
+			Description: sf.Tag.Get("desc"),
+			Type:        fieldType(sf.Type),
+		}
+		if field.Field == "" {
+			field.Field = strings.Split(sf.Tag.Get("json"), ",")[0]
+		}
+		if field.Field == "" || field.Field == "-" {
+			field.Field = sf.Name
+		}
+		if enum := sf.Tag.Get("enum"); enum != "" {
+			field.Enum = strings.Split(enum, ",")
+		}
+		if example, ok := sf.Tag.Lookup("example"); ok {
+			field.Example = example
+		}
+
+		for _, opt := range parts[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch key {
+			case "required":
+				field.Required = true
+			case "type":
+				field.Type = value
+			case "format":
+				field.Format = value
+			case "width":
+				field.Width = ztype.ToFloat64(value)
+			case "":
+			default:
+				return Schema{}, errors.New("unsupported tag option: " + key)
+			}
+		}
+		s.Fields = append(s.Fields, field)
+	}
+	return s, nil
+}
+
+// fieldType returns the column type of a Go type
+func fieldType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ColumnTypeInt
+	case reflect.Float32, reflect.Float64:
+		return ColumnTypeFloat
+	case reflect.Bool:
+		return ColumnTypeBool
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return ColumnTypeDate
+	}
+	return ColumnTypeString
+}
+
+// toSchema accepts a Schema, a *Schema, fields or a struct
+func toSchema(v interface{}) (Schema, error) {
+	switch val := v.(type) {
+	case Schema:
+		return val, nil
+	case *Schema:
+		if val != nil {
+			return *val, nil
+		}
+	case []TemplateField:
+		return Schema{Fields: val}, nil
+	default:
+		return ParseSchema(v)
+	}
+	return Schema{}, errors.New("schema is nil")
+}
+
+// Template produces a blank import workbook from a Schema, fields or a
+// struct: labelled headers with required markers and description comments,
+// example rows, enum dropdowns, column formats, an instructions sheet and a
+// locked header. Read it back with the ReadOptions of the same schema.
+func Template(schema interface{}) ([]byte, error) {
+	s, err := toSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.template()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// TemplateFile writes the import template of the schema to the path
+func TemplateFile(path string, schema interface{}) error {
+	b, err := Template(schema)
+	if err != nil {
+		return err
+	}
+	return zfile.WriteFile(path, b)
+}
+
+func (s Schema) sheet() string {
+	if s.Sheet == "" {
+		return "Sheet1"
+	}
+	return s.Sheet
+}
+
+func (f TemplateField) format() string {
+	if f.Format != "" {
+		return f.Format
+	}
+	switch f.Type {
+	case ColumnTypeInt:
+		return "0"
+	case ColumnTypeDate:
+		return DefaultDateFormat
+	case ColumnTypeDateTime:
+		return DefaultDateTimeFormat
+	case ColumnTypeFloat, ColumnTypeBool:
+		return ""
+	}
+	// text keeps the leading zeros of codes and phone numbers
+	return "@"
+}
+
+func (f TemplateField) label() string {
+	if f.Label == "" {
+		return f.Field
+	}
+	return f.Label
+}
+
+// value converts the text of examples to the type of the field
+func (f TemplateField) value(v interface{}) interface{} {
+	text, ok := v.(string)
+	if !ok || text == "" {
+		return v
+	}
+	switch f.Type {
+	case ColumnTypeInt:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case ColumnTypeFloat:
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			return n
+		}
+	case ColumnTypeBool:
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	case ColumnTypeDate, ColumnTypeDateTime:
+		if t, err := ztime.Parse(text); err == nil {
+			return t
+		}
+	}
+	return v
+}
+
+// examples returns the example rows converted to the types of the fields
+func (s Schema) examples() ztype.Maps {
+	rows := make(ztype.Maps, 0, len(s.Examples)+1)
+	first := ztype.Map{}
+	for _, field := range s.Fields {
+		if field.Example != nil {
+			first[field.Field] = field.value(field.Example)
+		}
+	}
+	if len(first) > 0 {
+		rows = append(rows, first)
+	}
+	for _, example := range s.Examples {
+		row := make(ztype.Map, len(s.Fields))
+		for _, field := range s.Fields {
+			if v, ok := example[field.Field]; ok {
+				row[field.Field] = field.value(v)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// headers returns the header text of every field
+func (s Schema) headers() []string {
+	marker := s.RequiredMarker
+	if marker == "" {
+		marker = DefaultRequiredMarker
+	}
+	headers := make([]string, len(s.Fields))
+	for i, field := range s.Fields {
+		headers[i] = field.label()
+		if field.Required {
+			headers[i] = marker + headers[i]
+		}
+	}
+	return headers
+}
+
+func (s Schema) template() (*excelize.File, error) {
+	if len(s.Fields) == 0 {
+		return nil, errors.New("schema has no fields")
+	}
+
+	sheet := s.sheet()
+	headers := s.headers()
+	keys := make([]string, len(s.Fields))
+	columns := make([]ColumnSpec, len(s.Fields))
+	validations := map[string]Validation{}
+	for i, field := range s.Fields {
+		if field.Field == "" {
+			return nil, errors.New("schema field has no key")
+		}
+		keys[i] = field.Field
+		columns[i] = ColumnSpec{Field: field.Field, Label: headers[i], NumFmt: field.format(), Width: field.Width}
+
+		list := field.Enum
+		if len(list) == 0 && field.Type == ColumnTypeBool {
+			list = []string{"TRUE", "FALSE"}
+		}
+		switch {
+		case field.Validation != nil:
+			validations[field.Field] = *field.Validation
+		case len(list) > 0:
+			validations[field.Field] = Validation{
+				List:         list,
+				Required:     field.Required,
+				ErrorTitle:   field.label(),
+				ErrorMessage: strings.Join(list, ", "),
+			}
+		}
+	}
+
+	examples := s.examples()
+	rows := examples
+	if len(rows) == 0 {
+		rows = ztype.Maps{{}}
+	}
+
+	headerStyle := &excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#DDEBF7"}},
+	}
+	f := excelize.NewFile()
+	err := write(f, rows, func(o *WriteOptions) {
+		o.Sheet = sheet
+		o.First = keys
+		o.Columns = columns
+		o.Validations = validations
+		o.HeaderStyle = headerStyle
+		o.FreezeHeader = true
+		o.AutoWidth = true
+		o.MinWidth = 12
+		o.Protect = &Protection{
+			Password:        s.Password,
+			AllowFormat:     true,
+			AllowInsertRows: true,
+			AllowDeleteRows: true,
+			AllowSort:       true,
+			AllowAutoFilter: true,
+		}
+	})
+	if err == nil {
+		err = s.decorate(f, sheet, len(examples))
+	}
+	if err == nil {
+		err = s.instructions(f, headerStyle)
+	}
+	if err == nil && sheet != "Sheet1" {
+		err = f.DeleteSheet("Sheet1")
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	index, _ := f.GetSheetIndex(sheet)
+	f.SetActiveSheet(index)
+	return f, nil
+}
+
+// decorate marks the required headers, comments the described ones and
+// greys out the example rows
+func (s Schema) decorate(f *excelize.File, sheet string, examples int) error {
+	marker := s.RequiredMarker
+	if marker == "" {
+		marker = DefaultRequiredMarker
+	}
+
+	for i, field := range s.Fields {
+		cell := ToCol(i) + "1"
+		if field.Required {
+			err := f.SetCellRichText(sheet, cell, []excelize.RichTextRun{
+				{Text: marker, Font: &excelize.Font{Bold: true, Color: "#FF0000"}},
+				{Text: field.label(), Font: &excelize.Font{Bold: true}},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if field.Description != "" {
+			err := f.AddComment(sheet, excelize.Comment{Cell: cell, Text: field.Description})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	styles := map[int]int{}
+	for row := 2; row < examples+2; row++ {
+		for i := range s.Fields {
+			cell := ToCol(i) + strconv.Itoa(row)
+			id, err := f.GetCellStyle(sheet, cell)
+			if err != nil {
+				return err
+			}
+			if _, ok := styles[id]; !ok {
+				style, err := f.GetStyle(id)
+				if err != nil {
+					return err
+				}
+				style.Font = &excelize.Font{Italic: true, Color: "#808080"}
+				if styles[id], err = f.NewStyle(style); err != nil {
+					return err
+				}
+			}
+			if err = f.SetCellStyle(sheet, cell, cell, styles[id]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// typeNames are the type descriptions of the instructions sheet
+var typeNames = map[string]string{
+	ColumnTypeString:   "Text",
+	ColumnTypeInt:      "Integer",
+	ColumnTypeFloat:    "Number",
+	ColumnTypeBool:     "TRUE / FALSE",
+	ColumnTypeDate:     "Date",
+	ColumnTypeDateTime: "Date and time",
+}
+
+// instructions writes the read-only sheet describing every field
+func (s Schema) instructions(f *excelize.File, headerStyle *excelize.Style) error {
+	sheet := s.InstructionsSheet
+	if sheet == "" {
+		sheet = DefaultInstructionsSheet
+	}
+
+	headers := s.headers()
+	examples := s.examples()
+	rows := make(ztype.Maps, len(s.Fields))
+	for i, field := range s.Fields {
+		typ := field.Type
+		if typ == "" {
+			typ = ColumnTypeString
+		}
+		format := field.format()
+		if format == "@" {
+			format = ""
+		}
+		example := ""
+		if len(examples) > 0 {
+			example = exampleText(field, examples[0][field.Field])
+		}
+		required := ""
+		if field.Required {
+			required = "Yes"
+		}
+		rows[i] = ztype.Map{
+			"column":      headers[i],
+			"key":         field.Field,
+			"required":    required,
+			"type":        typeNames[typ],
+			"format":      format,
+			"options":     strings.Join(field.Enum, ", "),
+			"description": field.Description,
+			"example":     example,
+		}
+	}
+
+	err := write(f, rows, func(o *WriteOptions) {
+		o.Sheet = sheet
+		o.Columns = []ColumnSpec{
+			{Field: "column", Label: "Column"},
+			{Field: "key", Label: "Key"},
+			{Field: "required", Label: "Required"},
+			{Field: "type", Label: "Type"},
+			{Field: "format", Label: "Format", NumFmt: "@"},
+			{Field: "options", Label: "Options", Wrap: true, Width: 30},
+			{Field: "description", Label: "Description", Wrap: true, Width: 50},
+			{Field: "example", Label: "Example", NumFmt: "@"},
+		}
+		o.HeaderStyle = headerStyle
+		o.AutoWidth = true
+		o.MinWidth = 10
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, note := range s.Notes {
+		if err = f.SetCellStr(sheet, "A"+strconv.Itoa(len(rows)+3+i), note); err != nil {
+			return err
+		}
+	}
+	return f.ProtectSheet(sheet, &excelize.SheetProtectionOptions{
+		Password:            s.Password,
+		SelectLockedCells:   true,
+		SelectUnlockedCells: true,
+	})
+}
+
+// exampleText returns the example value as shown in the template
+func exampleText(field TemplateField, v interface{}) string {
+	if t, ok := v.(time.Time); ok {
+		if field.Type == ColumnTypeDateTime {
+			return t.Format("2006-01-02 15:04:05")
+		}
+		return t.Format("2006-01-02")
+	}
+	if v == nil {
+		return ""
+	}
+	return ztype.ToString(v)
+}
+
+// ReadOptions reads the data sheet of a filled in template back to the keys
+// of the fields. Empty rows are dropped, and so are rows identical to an
+// example row, silently, as they are taken for examples left in the upload.
+// Fields, Sheet and HeaderMaps entries set by the caller are kept and the
+// Handler set before runs on the rows that are kept.
+func (s Schema) ReadOptions(o *ReadOptions) {
+	if o.Sheet == "" {
+		o.Sheet = s.sheet()
+	}
+	o.RemoveEmptyRow = true
+	if len(o.Fields) == 0 {
+		o.Fields = make([]string, len(s.Fields))
+		for i, field := range s.Fields {
+			o.Fields[i] = field.Field
+		}
+	}
+	headerMaps := make(map[string]string, len(s.Fields)*2+len(o.HeaderMaps))
+	for i, header := range s.headers() {
+		headerMaps[header] = s.Fields[i].Field
+		headerMaps[s.Fields[i].label()] = s.Fields[i].Field
+	}
+	for k, v := range o.HeaderMaps {
+		headerMaps[k] = v
+	}
+	o.HeaderMaps = headerMaps
+
+	examples := s.exampleRows()
+	if len(examples) == 0 {
+		return
+	}
+	fields, handler := o.Fields, o.Handler
+	o.Handler = func(index int, data ztype.Map) ztype.Map {
+		for _, example := range examples {
+			same := true
+			for _, field := range fields {
+				if ztype.ToString(data[field]) != example[field] {
+					same = false
+					break
+				}
+			}
+			if same {
+				return ztype.Map{}
+			}
+		}
+		if handler != nil {
+			return handler(index, data)
+		}
+		return data
+	}
+}
+
+// exampleRows returns the text of the example rows as Read returns it from a
+// template, the examples are written with the formats of the fields only
+func (s Schema) exampleRows() []map[string]string {
+	examples := s.examples()
+	if len(examples) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(s.Fields))
+	columns := make([]ColumnSpec, len(s.Fields))
+	for i, field := range s.Fields {
+		keys[i] = field.Field
+		columns[i] = ColumnSpec{Field: field.Field, NumFmt: field.format()}
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	err := write(f, examples, func(o *WriteOptions) {
+		o.First = keys
+		o.Columns = columns
+	})
+	if err != nil {
+		return nil
+	}
+	rows, err := f.GetRows("Sheet1")
+	if err != nil || len(rows) < 2 {
+		return nil
+	}
+
+	texts := make([]map[string]string, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		text := make(map[string]string, len(keys))
+		for i, key := range keys {
+			if i < len(row) {
+				text[key] = row[i]
+			} else {
+				text[key] = ""
+			}
+		}
+		texts = append(texts, text)
+	}
+	return texts
+}
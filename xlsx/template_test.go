@@ -0,0 +1,160 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+type importUser struct {
+	Name     string    `xlsx:"name,required" label:"姓名" desc:"真实姓名" example:"张三"`
+	Gender   string    `xlsx:"gender" label:"性别" enum:"男,女" example:"男"`
+	Birthday time.Time `xlsx:"birthday" label:"生日" example:"2000-01-02"`
+	Amount   float64   `xlsx:"amount,format=0.00" label:"金额" example:"12.5"`
+	Code     string    `json:"code" example:"007"`
+	Internal string    `xlsx:"-"`
+}
+
+func TestParseSchema(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	s, err := xlsx.ParseSchema(&importUser{})
+	tt.NoError(err)
+	tt.Equal(5, len(s.Fields))
+	tt.Equal("name", s.Fields[0].Field)
+	tt.EqualTrue(s.Fields[0].Required)
+	tt.Equal("真实姓名", s.Fields[0].Description)
+	tt.Equal([]string{"男", "女"}, s.Fields[1].Enum)
+	tt.Equal(xlsx.ColumnTypeDate, s.Fields[2].Type)
+	tt.Equal(xlsx.ColumnTypeFloat, s.Fields[3].Type)
+	tt.Equal("0.00", s.Fields[3].Format)
+	tt.Equal("code", s.Fields[4].Field)
+
+	_, err = xlsx.ParseSchema([]importUser{})
+	tt.NoError(err)
+	_, err = xlsx.ParseSchema("name")
+	tt.EqualTrue(err != nil)
+	_, err = xlsx.ParseSchema(struct {
+		Name string `xlsx:"name,unknown"`
+	}{})
+	tt.EqualTrue(err != nil)
+}
+
+func TestTemplate(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	s, err := xlsx.ParseSchema(importUser{})
+	tt.NoError(err)
+	s.Sheet = "用户"
+	s.Notes = []string{"请勿修改表头"}
+	b, err := xlsx.Template(s)
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	tt.Equal([]string{"用户", "Instructions"}, f.GetSheetList())
+	tt.Equal("用户", f.GetSheetName(f.GetActiveSheetIndex()))
+
+	rows, err := f.GetRows("用户")
+	tt.NoError(err)
+	tt.Equal([]string{"*姓名", "性别", "生日", "金额", "code"}, rows[0])
+	tt.Equal([]string{"张三", "男", "2000-01-02", "12.50", "007"}, rows[1])
+
+	comments, err := f.GetComments("用户")
+	tt.NoError(err)
+	tt.Equal(1, len(comments))
+	tt.Equal("A1", comments[0].Cell)
+
+	dvs, err := f.GetDataValidations("用户")
+	tt.NoError(err)
+	tt.Equal(1, len(dvs))
+	tt.Equal("B2:B1048576", dvs[0].Sqref)
+
+	// the header is locked and the input cells below are open
+	id, err := f.GetCellStyle("用户", "A1")
+	tt.NoError(err)
+	style, err := f.GetStyle(id)
+	tt.NoError(err)
+	tt.EqualTrue(style.Protection == nil || style.Protection.Locked)
+	id, err = f.GetColStyle("用户", "C")
+	tt.NoError(err)
+	style, err = f.GetStyle(id)
+	tt.NoError(err)
+	tt.EqualTrue(style.Protection != nil && !style.Protection.Locked)
+	tt.Equal("yyyy-mm-dd", *style.CustomNumFmt)
+
+	info, err := f.GetRows("Instructions")
+	tt.NoError(err)
+	tt.Equal([]string{"Column", "Key", "Required", "Type", "Format", "Options", "Description", "Example"}, info[0])
+	tt.Equal([]string{"*姓名", "name", "Yes", "Text", "", "", "真实姓名", "张三"}, info[1])
+	tt.Equal("男, 女", info[2][5])
+	tt.Equal("2000-01-02", info[3][7])
+	tt.Equal("请勿修改表头", info[len(info)-1][0])
+
+	// a user fills in the template below the example row
+	tt.NoError(f.SetCellValue("用户", "A3", "李四"))
+	tt.NoError(f.SetCellValue("用户", "B3", "女"))
+	tt.NoError(f.SetCellValue("用户", "D3", 8))
+	tt.NoError(f.SetCellValue("用户", "E3", "012"))
+	path := "./testdata/template.xlsx"
+	tt.NoError(f.SaveAs(path))
+	f.Close()
+	defer os.Remove(path)
+
+	data, err := xlsx.Read(path, s.ReadOptions)
+	tt.NoError(err)
+	tt.Equal(1, len(data))
+	tt.Equal(ztype.Map{"name": "李四", "gender": "女", "birthday": "", "amount": "8.00", "code": "012"}, data[0])
+
+	// the handler and fields of the caller are kept, example rows are still dropped
+	indexes := []int{}
+	data, err = xlsx.Read(path, func(o *xlsx.ReadOptions) {
+		o.Fields = []string{"name", "amount"}
+		o.Handler = func(index int, row ztype.Map) ztype.Map {
+			indexes = append(indexes, index)
+			row["checked"] = true
+			return row
+		}
+	}, s.ReadOptions)
+	tt.NoError(err)
+	tt.Equal(1, len(data))
+	tt.Equal(ztype.Map{"name": "李四", "amount": "8.00", "checked": true}, data[0])
+	tt.Equal([]int{1}, indexes)
+}
+
+func TestTemplateFields(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	fields := []xlsx.TemplateField{
+		{Field: "sku", Label: "SKU", Required: true},
+		{Field: "on_sale", Type: xlsx.ColumnTypeBool},
+		{Field: "qty", Type: xlsx.ColumnTypeInt, Validation: &xlsx.Validation{Type: xlsx.ValidationWhole, Min: 0, Max: 100}},
+	}
+	path := "./testdata/template_fields.xlsx"
+	tt.NoError(xlsx.TemplateFile(path, fields))
+	defer os.Remove(path)
+
+	f, err := excelize.OpenFile(path)
+	tt.NoError(err)
+	defer f.Close()
+	tt.Equal("Sheet1", f.GetSheetList()[0])
+	rows, err := f.GetRows("Sheet1")
+	tt.NoError(err)
+	tt.Equal([]string{"*SKU", "on_sale", "qty"}, rows[0])
+	dvs, err := f.GetDataValidations("Sheet1")
+	tt.NoError(err)
+	tt.Equal(2, len(dvs))
+
+	// a template uploaded without rows has no data
+	_, err = xlsx.Read(path, xlsx.Schema{Fields: fields}.ReadOptions)
+	tt.EqualTrue(err != nil)
+
+	_, err = xlsx.Template([]xlsx.TemplateField{})
+	tt.EqualTrue(err != nil)
+}
@@ -1,6 +1,10 @@
 package xlsx
 
-import "strings"
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
 
 // ToCol converts a column index to Excel-style column name (e.g., 0 -> A, 25 -> Z, 26 -> AA)
 func ToCol(i int) string {
@@ -28,3 +32,56 @@ func ToColIndex(col string) int {
 	}
 	return n - 1
 }
+
+// ToCell converts 0-based row and column indexes to a cell name (e.g., 0,0 -> A1, 6,1 -> B7)
+func ToCell(row, col int) string {
+	return ToCol(col) + strconv.Itoa(row+1)
+}
+
+// ParseCell converts a cell name to 0-based row and column indexes (e.g., B7 -> 6,1)
+func ParseCell(cell string) (row, col int, err error) {
+	cell = strings.TrimSpace(strings.ReplaceAll(cell, "$", ""))
+	i := strings.IndexFunc(cell, func(r rune) bool { return r >= '0' && r <= '9' })
+	if i <= 0 {
+		return 0, 0, errors.New("invalid cell name: " + cell)
+	}
+
+	col = ToColIndex(cell[:i])
+	row, err = strconv.Atoi(cell[i:])
+	if col < 0 || err != nil || row < 1 {
+		return 0, 0, errors.New("invalid cell name: " + cell)
+	}
+
+	return row - 1, col, nil
+}
+
+// isDateNumFmt reports whether the number format renders a date or time
+func isDateNumFmt(id int, custom string) bool {
+	if custom == "" {
+		return (id >= 14 && id <= 22) || (id >= 27 && id <= 36) || (id >= 45 && id <= 47) || (id >= 50 && id <= 58)
+	}
+
+	quoted := false
+	for i := 0; i < len(custom); i++ {
+		c := custom[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '\\' || c == '_' || c == '*':
+			i++
+		case c == '[':
+			end := strings.IndexByte(custom[i:], ']')
+			if end < 0 {
+				return false
+			}
+			i += end
+		default:
+			switch c | 0x20 {
+			case 'y', 'm', 'd', 'h', 's':
+				return true
+			}
+		}
+	}
+	return false
+}
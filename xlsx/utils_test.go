@@ -49,3 +49,38 @@ func TestToColIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestParseCell(t *testing.T) {
+	cases := map[string][2]int{
+		"A1":     {0, 0},
+		"b7":     {6, 1},
+		"$AA$10": {9, 26},
+	}
+	for s, want := range cases {
+		row, col, err := ParseCell(s)
+		if err != nil || row != want[0] || col != want[1] {
+			t.Fatalf("ParseCell(%q) = %d, %d, %v, want %v", s, row, col, err, want)
+		}
+		if ToCell(row, col) != strings.ToUpper(strings.ReplaceAll(s, "$", "")) {
+			t.Fatalf("ToCell(%d, %d) = %q", row, col, ToCell(row, col))
+		}
+	}
+
+	for _, s := range []string{"", "A", "1", "A0", "1A", "A-1"} {
+		if _, _, err := ParseCell(s); err == nil {
+			t.Fatalf("ParseCell(%q) should fail", s)
+		}
+	}
+}
+
+func TestIsDateNumFmt(t *testing.T) {
+	if !isDateNumFmt(14, "") || !isDateNumFmt(22, "") || isDateNumFmt(2, "") {
+		t.Fatal("builtin date formats")
+	}
+	if !isDateNumFmt(0, "yyyy-mm-dd") || !isDateNumFmt(0, "[h]:mm") {
+		t.Fatal("custom date formats")
+	}
+	if isDateNumFmt(0, "[Red]0.00") || isDateNumFmt(0, `0.00"days"`) || isDateNumFmt(0, "General") {
+		t.Fatal("custom number formats")
+	}
+}
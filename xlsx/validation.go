@@ -0,0 +1,225 @@
+package xlsx
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+)
+
+// Validation types
+const (
+	ValidationList       = "list"
+	ValidationWhole      = "whole"
+	ValidationDecimal    = "decimal"
+	ValidationDate       = "date"
+	ValidationTime       = "time"
+	ValidationTextLength = "textLength"
+)
+
+// ValidationListSheet is the hidden sheet holding the lists that do not fit inline
+const ValidationListSheet = "_lists"
+
+// maxInlineList is the length limit of an inline list formula
+const maxInlineList = 255
+
+// Validation restricts the values of a column below the header
+type Validation struct {
+	// Min and Max bound whole, decimal, date, time and textLength rules, they
+	// accept numbers, time.Time for dates, or a formula string such as TODAY()
+	Min interface{}
+	Max interface{}
+	// Type is list, whole, decimal, date, time or textLength, list by default
+	Type string
+	// Operator is between by default, or notBetween, equal, notEqual,
+	// greaterThan, greaterThanOrEqual, lessThan and lessThanOrEqual
+	Operator string
+	// ErrorStyle is stop by default, or warning and information
+	ErrorStyle    string
+	ErrorTitle    string
+	ErrorMessage  string
+	PromptTitle   string
+	PromptMessage string
+	List          []string
+	// ListSheet stores the list on the hidden sheet even when it fits inline
+	ListSheet bool
+	// Required rejects blank cells
+	Required bool
+}
+
+var (
+	validationTypes = map[string]excelize.DataValidationType{
+		ValidationList:       excelize.DataValidationTypeList,
+		ValidationWhole:      excelize.DataValidationTypeWhole,
+		ValidationDecimal:    excelize.DataValidationTypeDecimal,
+		ValidationDate:       excelize.DataValidationTypeDate,
+		ValidationTime:       excelize.DataValidationTypeTime,
+		ValidationTextLength: excelize.DataValidationTypeTextLength,
+	}
+	validationOperators = map[string]excelize.DataValidationOperator{
+		"between":            excelize.DataValidationOperatorBetween,
+		"notBetween":         excelize.DataValidationOperatorNotBetween,
+		"equal":              excelize.DataValidationOperatorEqual,
+		"notEqual":           excelize.DataValidationOperatorNotEqual,
+		"greaterThan":        excelize.DataValidationOperatorGreaterThan,
+		"greaterThanOrEqual": excelize.DataValidationOperatorGreaterThanOrEqual,
+		"lessThan":           excelize.DataValidationOperatorLessThan,
+		"lessThanOrEqual":    excelize.DataValidationOperatorLessThanOrEqual,
+	}
+	validationErrorStyles = map[string]excelize.DataValidationErrorStyle{
+		"stop":        excelize.DataValidationErrorStyleStop,
+		"warning":     excelize.DataValidationErrorStyleWarning,
+		"information": excelize.DataValidationErrorStyleInformation,
+	}
+)
+
+// validationBound converts a bound to a number or formula accepted by SetRange
+func validationBound(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case time.Time:
+		return timeToExcel(val), nil
+	case *time.Time:
+		if val == nil {
+			return "", nil
+		}
+		return timeToExcel(*val), nil
+	case string:
+		return strings.TrimPrefix(val, "="), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return ztype.ToInt(val), nil
+	case float32, float64:
+		return ztype.ToFloat64(val), nil
+	}
+	return nil, errors.New("invalid validation bound: " + ztype.ToString(v))
+}
+
+// applyValidations adds the validations of the fields to their columns below
+// the header rows, lists too long to be inline go to the hidden list sheet
+func applyValidations(f *excelize.File, sheet string, header []string, headerRows int, o WriteOptions) error {
+	if len(o.Validations) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(o.Validations))
+	for field := range o.Validations {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		i := slices.Index(header, field)
+		if i < 0 {
+			continue
+		}
+
+		v := o.Validations[field]
+		dv := excelize.NewDataValidation(!v.Required)
+		col := ToCol(i)
+		dv.SetSqref(col + strconv.Itoa(headerRows+1) + ":" + col + strconv.Itoa(excelize.TotalRows))
+
+		typ := v.Type
+		if typ == "" {
+			typ = ValidationList
+		}
+		if typ == ValidationList {
+			if err := setValidationList(f, dv, v); err != nil {
+				return err
+			}
+		} else {
+			t, ok := validationTypes[typ]
+			if !ok {
+				return errors.New("unsupported validation type: " + typ)
+			}
+			operator := v.Operator
+			if operator == "" {
+				operator = "between"
+			}
+			op, ok := validationOperators[operator]
+			if !ok {
+				return errors.New("unsupported validation operator: " + operator)
+			}
+			lower, err := validationBound(v.Min)
+			if err != nil {
+				return err
+			}
+			upper, err := validationBound(v.Max)
+			if err != nil {
+				return err
+			}
+			if operator != "between" && operator != "notBetween" && v.Min == nil {
+				lower = upper
+			}
+			if err = dv.SetRange(lower, upper, t, op); err != nil {
+				return err
+			}
+			if operator != "between" && operator != "notBetween" {
+				dv.Formula2 = ""
+			}
+		}
+
+		errorStyle := v.ErrorStyle
+		if errorStyle == "" {
+			errorStyle = "stop"
+		}
+		style, ok := validationErrorStyles[errorStyle]
+		if !ok {
+			return errors.New("unsupported validation error style: " + errorStyle)
+		}
+		dv.SetError(style, v.ErrorTitle, v.ErrorMessage)
+		if v.PromptTitle != "" || v.PromptMessage != "" {
+			dv.SetInput(v.PromptTitle, v.PromptMessage)
+		}
+
+		if err := f.AddDataValidation(sheet, dv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setValidationList(f *excelize.File, dv *excelize.DataValidation, v Validation) error {
+	inline := !v.ListSheet
+	size := 0
+	for _, item := range v.List {
+		size += len(item) + 1
+		if strings.ContainsAny(item, `,"`) {
+			inline = false
+		}
+	}
+	if inline && size-1 <= maxInlineList {
+		return dv.SetDropList(v.List)
+	}
+
+	index, err := f.GetSheetIndex(ValidationListSheet)
+	if err != nil {
+		return err
+	}
+	if index < 0 {
+		if _, err = f.NewSheet(ValidationListSheet); err != nil {
+			return err
+		}
+		if err = f.SetSheetVisible(ValidationListSheet, false); err != nil {
+			return err
+		}
+	}
+
+	cols, err := f.GetCols(ValidationListSheet)
+	if err != nil {
+		return err
+	}
+	col := ToCol(len(cols))
+	for r, item := range v.List {
+		if err = f.SetCellStr(ValidationListSheet, col+strconv.Itoa(r+1), item); err != nil {
+			return err
+		}
+	}
+	dv.SetSqrefDropList("'" + ValidationListSheet + "'!$" + col + "$1:$" + col + "$" + strconv.Itoa(max(len(v.List), 1)))
+	return nil
+}
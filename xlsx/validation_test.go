@@ -0,0 +1,89 @@
+package xlsx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func TestWriteValidations(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	cities := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		cities = append(cities, "城市"+ztype.ToString(i))
+	}
+	data := ztype.Maps{
+		{"status": "paid", "city": "城市1", "qty": 1, "day": time.Now(), "remark": "ok"},
+	}
+	b, err := xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.First = []string{"status", "city", "qty", "day", "remark"}
+		wo.Validations = map[string]xlsx.Validation{
+			"status": {List: []string{"paid", "unpaid"}, PromptTitle: "状态", PromptMessage: "请选择"},
+			"city":   {List: cities},
+			"qty":    {Type: xlsx.ValidationWhole, Min: 1, Max: 999, ErrorTitle: "数量", ErrorMessage: "1 - 999", Required: true},
+			"day":    {Type: xlsx.ValidationDate, Operator: "greaterThanOrEqual", Min: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			"remark": {Type: xlsx.ValidationTextLength, Operator: "lessThanOrEqual", Max: 10, ErrorStyle: "warning"},
+			"none":   {List: []string{"x"}},
+		}
+	})
+	tt.NoError(err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	tt.NoError(err)
+	defer f.Close()
+
+	dvs, err := f.GetDataValidations("Sheet1")
+	tt.NoError(err)
+	tt.Equal(5, len(dvs))
+
+	bySqref := map[string]*excelize.DataValidation{}
+	for _, dv := range dvs {
+		bySqref[strings.Split(dv.Sqref, ":")[0]] = dv
+	}
+
+	status := bySqref["A2"]
+	tt.Equal("A2:A1048576", status.Sqref)
+	tt.Equal("list", status.Type)
+	tt.Equal(`"paid,unpaid"`, status.Formula1)
+	tt.Equal("请选择", *status.Prompt)
+
+	city := bySqref["B2"]
+	tt.Equal("'_lists'!$A$1:$A$100", city.Formula1)
+	visible, err := f.GetSheetVisible(xlsx.ValidationListSheet)
+	tt.NoError(err)
+	tt.EqualTrue(!visible)
+	v, _ := f.GetCellValue(xlsx.ValidationListSheet, "A100")
+	tt.Equal("城市99", v)
+	tt.Equal("Sheet1", f.GetSheetName(f.GetActiveSheetIndex()))
+
+	qty := bySqref["C2"]
+	tt.Equal("whole", qty.Type)
+	tt.Equal("between", qty.Operator)
+	tt.Equal("1", qty.Formula1)
+	tt.Equal("999", qty.Formula2)
+	tt.EqualTrue(!qty.AllowBlank)
+	tt.Equal("1 - 999", *qty.Error)
+
+	day := bySqref["D2"]
+	tt.Equal("date", day.Type)
+	tt.Equal("greaterThanOrEqual", day.Operator)
+	tt.Equal("45658", day.Formula1)
+	tt.Equal("", day.Formula2)
+
+	remark := bySqref["E2"]
+	tt.Equal("textLength", remark.Type)
+	tt.Equal("10", remark.Formula1)
+	tt.Equal("warning", *remark.ErrorStyle)
+
+	_, err = xlsx.Write(data, func(wo *xlsx.WriteOptions) {
+		wo.Validations = map[string]xlsx.Validation{"qty": {Type: "unknown"}}
+	})
+	tt.EqualTrue(err != nil)
+}
@@ -0,0 +1,220 @@
+package xlsx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Default number formats of time values
+const (
+	DefaultDateFormat     = "yyyy-mm-dd"
+	DefaultDateTimeFormat = "yyyy-mm-dd hh:mm:ss"
+)
+
+// maxExactDigits is the number of significant digits Excel keeps for numbers
+const maxExactDigits = 15
+
+var decimalPattern = regexp.MustCompile(`^[-+]?\d+(\.\d+)?$`)
+
+type (
+	// decimalValue is a decimal type such as shopspring/decimal, written as
+	// an exact numeric, other Stringers are written as text
+	decimalValue interface {
+		fmt.Stringer
+		Coefficient() *big.Int
+		Exponent() int32
+	}
+	// cellWrite is a value prepared for writing along with its number format
+	cellWrite struct {
+		value   interface{}
+		link    *Link
+		numFmt  string
+		numeric string
+	}
+	// numFmtStyles caches the style of every number format used by a write,
+	// number formats of cells are applied on top of the style of their column
+	numFmtStyles struct {
+		f       *excelize.File
+		styles  map[[2]interface{}]int
+		columns map[int]*excelize.Style
+		fixed   map[int]bool
+	}
+)
+
+func newNumFmtStyles(f *excelize.File) *numFmtStyles {
+	return &numFmtStyles{
+		f:       f,
+		styles:  map[[2]interface{}]int{},
+		columns: map[int]*excelize.Style{},
+		fixed:   map[int]bool{},
+	}
+}
+
+// setColumn records the style of a column, a column with its own number
+// format keeps it for every cell
+func (s *numFmtStyles) setColumn(col int, style *excelize.Style, numFmt bool) {
+	s.columns[col] = style
+	s.fixed[col] = numFmt
+}
+
+func (s *numFmtStyles) get(col int, numFmt string) (int, error) {
+	base, ok := s.columns[col]
+	if !ok {
+		col = -1
+	}
+	key := [2]interface{}{col, numFmt}
+	if id, ok := s.styles[key]; ok {
+		return id, nil
+	}
+
+	style := &excelize.Style{}
+	if base != nil {
+		b := *base
+		style = &b
+	}
+	style.CustomNumFmt, style.NumFmt = &numFmt, 0
+	if numFmt == "@" {
+		style.CustomNumFmt, style.NumFmt = nil, 49
+	}
+	id, err := s.f.NewStyle(style)
+	if err != nil {
+		return 0, err
+	}
+	s.styles[key] = id
+	return id, nil
+}
+
+// writeValue converts a value to what is stored in the cell of the field
+func writeValue(field string, v interface{}, o *WriteOptions) cellWrite {
+	switch val := v.(type) {
+	case nil:
+		if o.NilValue != nil {
+			placeholder := *o
+			placeholder.NilValue = nil
+			return writeValue(field, o.NilValue, &placeholder)
+		}
+		return cellWrite{}
+	case *time.Time:
+		if val == nil {
+			return writeValue(field, nil, o)
+		}
+		return writeValue(field, *val, o)
+	case time.Time:
+		if format, ok := o.DateFormats[field]; ok {
+			return cellWrite{value: val, numFmt: format}
+		}
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
+			return cellWrite{value: val, numFmt: o.DateFormat}
+		}
+		return cellWrite{value: val, numFmt: o.DateTimeFormat}
+	case int:
+		return writeInt(strconv.FormatInt(int64(val), 10), v, o)
+	case int64:
+		return writeInt(strconv.FormatInt(val, 10), v, o)
+	case uint:
+		return writeInt(strconv.FormatUint(uint64(val), 10), v, o)
+	case uint64:
+		return writeInt(strconv.FormatUint(val, 10), v, o)
+	case *big.Int:
+		if val == nil {
+			return writeValue(field, nil, o)
+		}
+		return writeInt(val.String(), val.String(), o)
+	case json.Number:
+		return writeDecimal(val.String(), v, o)
+	case Link:
+		return cellWrite{value: val.text(), link: &val}
+	case *Link:
+		if val == nil {
+			return writeValue(field, nil, o)
+		}
+		return cellWrite{value: val.text(), link: val}
+	case string, []byte, bool, float32, float64, int8, int16, int32, uint8, uint16, uint32:
+		return cellWrite{value: v}
+	case *big.Float:
+		if val == nil {
+			return writeValue(field, nil, o)
+		}
+		return writeDecimal(val.Text('f', -1), v, o)
+	case *big.Rat:
+		if val == nil {
+			return writeValue(field, nil, o)
+		}
+		if n, exact := val.FloatPrec(); exact {
+			return writeDecimal(val.FloatString(n), v, o)
+		}
+		f, _ := val.Float64()
+		return cellWrite{value: f}
+	case decimalValue:
+		return writeDecimal(val.String(), v, o)
+	}
+	return cellWrite{value: v}
+}
+
+func writeInt(digits string, v interface{}, o *WriteOptions) cellWrite {
+	if o.StringifyLargeInts && len(strings.TrimLeft(digits, "-")) > maxExactDigits {
+		return cellWrite{value: digits, numFmt: "@"}
+	}
+	if _, ok := v.(string); ok {
+		return cellWrite{numeric: digits}
+	}
+	return cellWrite{value: v}
+}
+
+func writeDecimal(s string, v interface{}, o *WriteOptions) cellWrite {
+	if !decimalPattern.MatchString(s) {
+		return cellWrite{value: v}
+	}
+	s = strings.TrimPrefix(s, "+")
+	if !strings.Contains(s, ".") {
+		return writeInt(s, s, o)
+	}
+	return cellWrite{numeric: s}
+}
+
+// setRow writes the prepared values of a row, numeric text is stored as is
+// so decimals keep every digit
+func setRow(f *excelize.File, sheet string, row int, values []cellWrite, styles *numFmtStyles) error {
+	plain := make([]interface{}, len(values))
+	for i := range values {
+		plain[i] = values[i].value
+	}
+	if err := f.SetSheetRow(sheet, "A"+strconv.Itoa(row), &plain); err != nil {
+		return err
+	}
+
+	for i := range values {
+		if values[i].numeric == "" && values[i].numFmt == "" && values[i].link == nil {
+			continue
+		}
+		cell := ToCol(i) + strconv.Itoa(row)
+		if values[i].link != nil {
+			if err := setLink(f, sheet, cell, i, *values[i].link, styles, false); err != nil {
+				return err
+			}
+			continue
+		}
+		if values[i].numeric != "" {
+			if err := f.SetCellDefault(sheet, cell, values[i].numeric); err != nil {
+				return err
+			}
+		}
+		if values[i].numFmt != "" && !styles.fixed[i] {
+			style, err := styles.get(i, values[i].numFmt)
+			if err != nil {
+				return err
+			}
+			if err = f.SetCellStyle(sheet, cell, cell, style); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
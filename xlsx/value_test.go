@@ -0,0 +1,93 @@
+package xlsx_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/xuri/excelize/v2"
+	"github.com/zlsgo/office/xlsx"
+)
+
+type decimal struct {
+	text string
+}
+
+func (d decimal) String() string {
+	return d.text
+}
+
+func (d decimal) Coefficient() *big.Int { return nil }
+
+func (d decimal) Exponent() int32 { return 0 }
+
+type level int
+
+func (l level) String() string {
+	return strconv.Itoa(int(l) + 1)
+}
+
+func TestWriteTypedValues(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_typed.xlsx"
+	defer os.Remove(testFile)
+
+	day := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2025, 3, 8, 9, 30, 15, 0, time.UTC)
+	id, _ := new(big.Int).SetString("123456789012345678901234", 10)
+	data := ztype.Maps{
+		{"id": int64(310101199001011234), "day": day, "at": at, "month": day, "price": decimal{"12345678901.123456789"}, "qty": json.Number("3"), "remark": nil, "big": id, "name": decimal{"n/a"}},
+		{"id": int64(42), "day": &day, "at": (*time.Time)(nil), "month": at, "price": decimal{"-0.10"}, "qty": 7, "remark": "ok", "big": uint64(7), "name": level(1)},
+		{"price": new(big.Float).SetFloat64(0.5), "qty": big.NewRat(1, 4), "big": big.NewRat(1, 3)},
+	}
+	tt.NoError(xlsx.WriteFile(testFile, data, func(wo *xlsx.WriteOptions) {
+		wo.StringifyLargeInts = true
+		wo.NilValue = "-"
+		wo.DateFormats = map[string]string{"month": "yyyy/mm"}
+	}))
+
+	rows, err := xlsx.Read(testFile)
+	tt.NoError(err)
+	tt.Equal(3, len(rows))
+	tt.Equal("310101199001011234", rows[0].Get("id").String())
+	tt.Equal("2025-03-08", rows[0].Get("day").String())
+	tt.Equal("2025-03-08 09:30:15", rows[0].Get("at").String())
+	tt.Equal("2025/03", rows[0].Get("month").String())
+	tt.Equal("-", rows[0].Get("remark").String())
+	tt.Equal("123456789012345678901234", rows[0].Get("big").String())
+	tt.Equal("n/a", rows[0].Get("name").String())
+	tt.Equal(3, rows[0].Get("qty").Int())
+	tt.Equal("42", rows[1].Get("id").String())
+	tt.Equal("2025-03-08", rows[1].Get("day").String())
+	tt.Equal("-", rows[1].Get("at").String())
+	tt.Equal("-0.1", rows[1].Get("price").String())
+	tt.Equal("0.5", rows[2].Get("price").String())
+	tt.Equal("0.25", rows[2].Get("qty").String())
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+
+	raw, err := f.Engine().GetCellValue("Sheet1", "G2", excelize.Options{RawCellValue: true})
+	tt.NoError(err)
+	tt.Equal("12345678901.123456789", raw)
+	typ, _ := f.Engine().GetCellType("Sheet1", "G2")
+	tt.Equal(excelize.CellTypeUnset, typ)
+	// Stringers other than decimal types are written as text
+	typ, _ = f.Engine().GetCellType("Sheet1", "F3")
+	tt.Equal(excelize.CellTypeSharedString, typ)
+
+	tt.Equal(int64(42), f.Get("Sheet1", "D3").Value())
+	tt.Equal(at, f.Get("Sheet1", "A2").Value())
+	tt.Equal(day, f.Get("Sheet1", "C3").Value())
+
+	b, err := xlsx.Write(ztype.Maps{{"id": int64(310101199001011234)}})
+	tt.NoError(err)
+	tt.EqualTrue(len(b) > 0)
+}
@@ -0,0 +1,542 @@
+package xlsx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+	"github.com/xuri/excelize/v2"
+)
+
+// BIFF8 record types
+const (
+	biffFormula    = 0x0006
+	biffEOF        = 0x000A
+	biffDateMode   = 0x0022
+	biffFilePass   = 0x002F
+	biffContinue   = 0x003C
+	biffBoundSheet = 0x0085
+	biffMulRK      = 0x00BD
+	biffRString    = 0x00D6
+	biffXF         = 0x00E0
+	biffMergeCells = 0x00E5
+	biffSST        = 0x00FC
+	biffLabelSST   = 0x00FD
+	biffNumber     = 0x0203
+	biffLabel      = 0x0204
+	biffBoolErr    = 0x0205
+	biffString     = 0x0207
+	biffRK         = 0x027E
+	biffFormat     = 0x041E
+	biffBOF        = 0x0809
+)
+
+var (
+	oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+	errNotXLS = errors.New("not a BIFF8 workbook")
+
+	xlsErrors = map[byte]string{
+		0x00: "#NULL!",
+		0x07: "#DIV/0!",
+		0x0F: "#VALUE!",
+		0x17: "#REF!",
+		0x1D: "#NAME?",
+		0x24: "#NUM!",
+		0x2A: "#N/A",
+	}
+)
+
+type (
+	xlsCell struct {
+		value interface{}
+		row   int
+		col   int
+		xf    int
+	}
+	xlsSheet struct {
+		name   string
+		cells  []xlsCell
+		merges [][4]int
+		offset int
+		state  byte
+	}
+	xlsBook struct {
+		formats  map[int]string
+		sheets   []*xlsSheet
+		sst      []string
+		xfs      []int
+		date1904 bool
+	}
+)
+
+// isOLEFile reports whether the file is an OLE compound document such as .xls
+func isOLEFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(oleMagic))
+	if _, err = io.ReadFull(f, magic); err != nil {
+		return false
+	}
+	return bytes.Equal(magic, oleMagic)
+}
+
+// openXLS converts a legacy BIFF8 workbook into an in-memory spreadsheet
+// so that it can be read with the same API as xlsx files
+func openXLS(path string) (*excelize.File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	doc, err := mscfb.New(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var stream []byte
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		switch entry.Name {
+		case "Workbook":
+			stream = make([]byte, entry.Size)
+			if _, err = io.ReadFull(entry, stream); err != nil {
+				return nil, err
+			}
+		case "Book":
+			return nil, errors.New("BIFF5 and earlier xls files are not supported")
+		}
+	}
+	if stream == nil {
+		return nil, errNotXLS
+	}
+
+	book, err := parseBIFF8(stream)
+	if err != nil {
+		return nil, err
+	}
+	return book.toFile()
+}
+
+func readRecord(b []byte, pos int) (id uint16, data []byte, next int, ok bool) {
+	if pos+4 > len(b) {
+		return 0, nil, pos, false
+	}
+	id = binary.LittleEndian.Uint16(b[pos:])
+	size := int(binary.LittleEndian.Uint16(b[pos+2:]))
+	if pos+4+size > len(b) {
+		return 0, nil, pos, false
+	}
+	return id, b[pos+4 : pos+4+size], pos + 4 + size, true
+}
+
+func parseBIFF8(b []byte) (*xlsBook, error) {
+	id, data, pos, ok := readRecord(b, 0)
+	if !ok || id != biffBOF || len(data) < 4 {
+		return nil, errors.New("invalid xls file")
+	}
+	if binary.LittleEndian.Uint16(data) != 0x0600 {
+		return nil, errors.New("only BIFF8 xls files are supported")
+	}
+
+	book := &xlsBook{formats: map[int]string{}}
+	for {
+		id, data, pos, ok = readRecord(b, pos)
+		if !ok || id == biffEOF {
+			break
+		}
+
+		switch id {
+		case biffFilePass:
+			return nil, errors.New("encrypted xls files are not supported")
+		case biffDateMode:
+			book.date1904 = len(data) >= 2 && binary.LittleEndian.Uint16(data) == 1
+		case biffFormat:
+			if len(data) > 2 {
+				book.formats[int(binary.LittleEndian.Uint16(data))], _ = xlsString(data[2:], 2)
+			}
+		case biffXF:
+			if len(data) >= 4 {
+				book.xfs = append(book.xfs, int(binary.LittleEndian.Uint16(data[2:])))
+			}
+		case biffBoundSheet:
+			if len(data) < 8 {
+				continue
+			}
+			name, _ := xlsString(data[6:], 1)
+			// only worksheets, chart and macro sheets have no cells to read
+			if data[5] == 0 {
+				book.sheets = append(book.sheets, &xlsSheet{
+					name:   name,
+					offset: int(binary.LittleEndian.Uint32(data)),
+					state:  data[4] & 0x03,
+				})
+			}
+		case biffSST:
+			segs := [][]byte{data}
+			for {
+				nid, ndata, npos, nok := readRecord(b, pos)
+				if !nok || nid != biffContinue {
+					break
+				}
+				segs = append(segs, ndata)
+				pos = npos
+			}
+			book.sst = parseSST(segs)
+		}
+	}
+
+	for _, sheet := range book.sheets {
+		book.parseSheet(b, sheet)
+	}
+	return book, nil
+}
+
+func (book *xlsBook) parseSheet(b []byte, sheet *xlsSheet) {
+	id, _, pos, ok := readRecord(b, sheet.offset)
+	if !ok || id != biffBOF {
+		return
+	}
+
+	pending := -1
+	for {
+		var data []byte
+		id, data, pos, ok = readRecord(b, pos)
+		if !ok || id == biffEOF {
+			return
+		}
+
+		if id == biffString && pending >= 0 {
+			sheet.cells[pending].value, _ = xlsString(data, 2)
+			pending = -1
+			continue
+		}
+		if len(data) < 6 {
+			continue
+		}
+
+		row := int(binary.LittleEndian.Uint16(data))
+		col := int(binary.LittleEndian.Uint16(data[2:]))
+		xf := int(binary.LittleEndian.Uint16(data[4:]))
+		add := func(value interface{}) {
+			sheet.cells = append(sheet.cells, xlsCell{row: row, col: col, xf: xf, value: value})
+		}
+
+		switch id {
+		case biffLabelSST:
+			if len(data) >= 10 {
+				if i := int(binary.LittleEndian.Uint32(data[6:])); i < len(book.sst) {
+					add(book.sst[i])
+				}
+			}
+		case biffLabel, biffRString:
+			if s, ok := xlsString(data[6:], 2); ok {
+				add(s)
+			}
+		case biffNumber:
+			if len(data) >= 14 {
+				add(math.Float64frombits(binary.LittleEndian.Uint64(data[6:])))
+			}
+		case biffRK:
+			if len(data) >= 10 {
+				add(rkValue(binary.LittleEndian.Uint32(data[6:])))
+			}
+		case biffMulRK:
+			for i := 4; i+6 <= len(data)-2; i += 6 {
+				sheet.cells = append(sheet.cells, xlsCell{
+					row:   row,
+					col:   col + (i-4)/6,
+					xf:    int(binary.LittleEndian.Uint16(data[i:])),
+					value: rkValue(binary.LittleEndian.Uint32(data[i+2:])),
+				})
+			}
+		case biffBoolErr:
+			if len(data) >= 8 {
+				if data[7] == 0 {
+					add(data[6] != 0)
+				} else {
+					add(xlsErrors[data[6]])
+				}
+			}
+		case biffFormula:
+			if len(data) < 14 {
+				continue
+			}
+			if data[12] != 0xFF || data[13] != 0xFF {
+				add(math.Float64frombits(binary.LittleEndian.Uint64(data[6:])))
+				continue
+			}
+			switch data[6] {
+			case 0:
+				add("")
+				pending = len(sheet.cells) - 1
+			case 1:
+				add(data[8] != 0)
+			case 2:
+				add(xlsErrors[data[8]])
+			}
+		case biffMergeCells:
+			n := int(binary.LittleEndian.Uint16(data))
+			for i := 0; i < n && 2+i*8+8 <= len(data); i++ {
+				r := data[2+i*8:]
+				sheet.merges = append(sheet.merges, [4]int{
+					int(binary.LittleEndian.Uint16(r)),
+					int(binary.LittleEndian.Uint16(r[2:])),
+					int(binary.LittleEndian.Uint16(r[4:])),
+					int(binary.LittleEndian.Uint16(r[6:])),
+				})
+			}
+		}
+	}
+}
+
+func (book *xlsBook) toFile() (*excelize.File, error) {
+	if len(book.sheets) == 0 {
+		return nil, errors.New("no sheet")
+	}
+
+	f := excelize.NewFile()
+	if book.date1904 {
+		date1904 := true
+		if err := f.SetWorkbookProps(&excelize.WorkbookPropsOptions{Date1904: &date1904}); err != nil {
+			return nil, err
+		}
+	}
+
+	styles := map[int]int{}
+	for i, sheet := range book.sheets {
+		var err error
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), sheet.name)
+		} else {
+			_, err = f.NewSheet(sheet.name)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range sheet.cells {
+			cell := ToCell(c.row, c.col)
+			switch v := c.value.(type) {
+			case float64:
+				err = f.SetCellFloat(sheet.name, cell, v, -1, 64)
+				if err == nil {
+					var style int
+					if style, err = book.numFmtStyle(f, styles, c.xf); err == nil && style > 0 {
+						err = f.SetCellStyle(sheet.name, cell, cell, style)
+					}
+				}
+			case bool:
+				err = f.SetCellBool(sheet.name, cell, v)
+			case string:
+				if v != "" {
+					err = f.SetCellStr(sheet.name, cell, v)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, m := range sheet.merges {
+			if err = f.MergeCell(sheet.name, ToCell(m[0], m[2]), ToCell(m[1], m[3])); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, sheet := range book.sheets {
+		if sheet.state != 0 {
+			_ = f.SetSheetVisible(sheet.name, false, sheet.state == 2)
+		}
+	}
+	return f, nil
+}
+
+// numFmtStyle returns a style carrying the number format of the XF record
+func (book *xlsBook) numFmtStyle(f *excelize.File, styles map[int]int, xf int) (int, error) {
+	if xf < 0 || xf >= len(book.xfs) || book.xfs[xf] == 0 {
+		return 0, nil
+	}
+	if id, ok := styles[xf]; ok {
+		return id, nil
+	}
+
+	numFmt := book.xfs[xf]
+	style := &excelize.Style{NumFmt: numFmt}
+	if code, ok := book.formats[numFmt]; ok {
+		style = &excelize.Style{CustomNumFmt: &code}
+	}
+
+	id, err := f.NewStyle(style)
+	if err != nil {
+		return 0, err
+	}
+	styles[xf] = id
+	return id, nil
+}
+
+func rkValue(rk uint32) float64 {
+	var v float64
+	if rk&0x02 != 0 {
+		v = float64(int32(rk) >> 2)
+	} else {
+		v = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		v /= 100
+	}
+	return v
+}
+
+// xlsString decodes a XLUnicodeString whose character count takes lenSize bytes
+func xlsString(b []byte, lenSize int) (string, bool) {
+	if len(b) < lenSize+1 {
+		return "", false
+	}
+	n := int(b[0])
+	if lenSize == 2 {
+		n = int(binary.LittleEndian.Uint16(b))
+	}
+	high := b[lenSize]&0x01 != 0
+	b = b[lenSize+1:]
+
+	if !high {
+		if len(b) < n {
+			n = len(b)
+		}
+		return latin1(b[:n]), true
+	}
+
+	if len(b) < n*2 {
+		n = len(b) / 2
+	}
+	u := make([]uint16, n)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(u)), true
+}
+
+func latin1(b []byte) string {
+	r := make([]rune, len(b))
+	for i := range b {
+		r[i] = rune(b[i])
+	}
+	return string(r)
+}
+
+// sstReader reads the shared string table across its CONTINUE records
+type sstReader struct {
+	segs [][]byte
+	seg  int
+	pos  int
+}
+
+func (r *sstReader) next(n int) []byte {
+	for r.seg < len(r.segs) && r.pos >= len(r.segs[r.seg]) {
+		r.seg++
+		r.pos = 0
+	}
+	if r.seg >= len(r.segs) || r.pos+n > len(r.segs[r.seg]) {
+		return nil
+	}
+	b := r.segs[r.seg][r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *sstReader) skip(n int) {
+	for n > 0 && r.seg < len(r.segs) {
+		rest := len(r.segs[r.seg]) - r.pos
+		if n < rest {
+			r.pos += n
+			return
+		}
+		n -= rest
+		r.seg++
+		r.pos = 0
+	}
+}
+
+// chars reads n characters, a CONTINUE record in the middle of the
+// characters starts with a new option byte telling their width
+func (r *sstReader) chars(n int, high bool) (string, bool) {
+	u := make([]uint16, 0, n)
+	for len(u) < n {
+		if r.seg >= len(r.segs) {
+			return "", false
+		}
+		seg := r.segs[r.seg]
+		if r.pos >= len(seg) {
+			r.seg++
+			if r.seg >= len(r.segs) || len(r.segs[r.seg]) == 0 {
+				return "", false
+			}
+			high = r.segs[r.seg][0]&0x01 != 0
+			r.pos = 1
+			continue
+		}
+		if high {
+			if r.pos+2 > len(seg) {
+				return "", false
+			}
+			u = append(u, binary.LittleEndian.Uint16(seg[r.pos:]))
+			r.pos += 2
+		} else {
+			u = append(u, uint16(seg[r.pos]))
+			r.pos++
+		}
+	}
+	return string(utf16.Decode(u)), true
+}
+
+func parseSST(segs [][]byte) []string {
+	if len(segs[0]) < 8 {
+		return nil
+	}
+
+	count := int(binary.LittleEndian.Uint32(segs[0][4:]))
+	r := &sstReader{segs: segs, pos: 8}
+	sst := make([]string, 0, min(count, 1<<16))
+	for i := 0; i < count; i++ {
+		h := r.next(3)
+		if h == nil {
+			break
+		}
+		n, flags := int(binary.LittleEndian.Uint16(h)), h[2]
+
+		runs, ext := 0, 0
+		if flags&0x08 != 0 {
+			b := r.next(2)
+			if b == nil {
+				break
+			}
+			runs = int(binary.LittleEndian.Uint16(b))
+		}
+		if flags&0x04 != 0 {
+			b := r.next(4)
+			if b == nil {
+				break
+			}
+			ext = int(binary.LittleEndian.Uint32(b))
+		}
+
+		s, ok := r.chars(n, flags&0x01 != 0)
+		if !ok {
+			break
+		}
+		sst = append(sst, s)
+		r.skip(runs*4 + ext)
+	}
+	return sst
+}
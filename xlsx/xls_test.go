@@ -0,0 +1,277 @@
+package xlsx_test
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/zlsgo/office/xlsx"
+)
+
+func biffRecord(id uint16, parts ...[]byte) []byte {
+	size := 0
+	for _, p := range parts {
+		size += len(p)
+	}
+	b := binary.LittleEndian.AppendUint16(nil, id)
+	b = binary.LittleEndian.AppendUint16(b, uint16(size))
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func u16(v ...int) []byte {
+	b := []byte{}
+	for _, i := range v {
+		b = binary.LittleEndian.AppendUint16(b, uint16(i))
+	}
+	return b
+}
+
+func u32(v int) []byte {
+	return binary.LittleEndian.AppendUint32(nil, uint32(v))
+}
+
+func f64(v float64) []byte {
+	return binary.LittleEndian.AppendUint64(nil, math.Float64bits(v))
+}
+
+func rk(v int) []byte {
+	return u32(v<<2 | 0x02)
+}
+
+func biffChars(s string) []byte {
+	high := false
+	for _, r := range s {
+		if r > 0xFF {
+			high = true
+		}
+	}
+	if !high {
+		b := []byte{0}
+		for _, r := range s {
+			b = append(b, byte(r))
+		}
+		return b
+	}
+	b := []byte{1}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+func biffString(s string) []byte {
+	return append(u16(len([]rune(s))), biffChars(s)...)
+}
+
+// buildXLS builds a BIFF8 workbook stream with two worksheets and a chart sheet
+func buildXLS() []byte {
+	bof := func(dt int) []byte { return biffRecord(0x0809, u16(0x0600, dt), make([]byte, 12)) }
+	eof := biffRecord(0x000A)
+	xf := func(numFmt int) []byte { return biffRecord(0x00E0, u16(0, numFmt), make([]byte, 16)) }
+	boundSheet := func(name string, state, dt byte) []byte {
+		return biffRecord(0x0085, u32(0), []byte{state, dt, byte(len([]rune(name)))}, biffChars(name))
+	}
+	labelSST := func(row, col, i int) []byte { return biffRecord(0x00FD, u16(row, col, 0), u32(i)) }
+
+	sst := biffRecord(0x00FC, u32(8), u32(8),
+		biffString("name"), biffString("score"), biffString("date"), biffString("张三"),
+		u16(11), []byte{0x08}, u16(1), []byte("hello "),
+	)
+	sst = append(sst, biffRecord(0x003C, biffChars("world"), u16(0, 0), biffString("rich"), biffString("x"), biffString("y"))...)
+
+	globals := [][]byte{
+		bof(0x0005),
+		biffRecord(0x0022, u16(0)),
+		biffRecord(0x041E, u16(164), biffString("yyyy-mm-dd")),
+		xf(0), xf(164), xf(14), xf(2),
+		boundSheet("数据", 0, 0),
+		boundSheet("Chart", 0, 2),
+		boundSheet("Hidden", 1, 0),
+		sst,
+		eof,
+	}
+
+	sheet1 := [][]byte{
+		bof(0x0010),
+		labelSST(0, 0, 0), labelSST(0, 1, 1), labelSST(0, 2, 2),
+		labelSST(1, 0, 3),
+		biffRecord(0x0203, u16(1, 1, 3), f64(95.5)),
+		biffRecord(0x027E, u16(1, 2, 1), rk(45658)),
+		labelSST(2, 0, 4),
+		biffRecord(0x00BD, u16(2, 1), u16(0), rk(80), u16(2), rk(45659), u16(2)),
+		biffRecord(0x0006, u16(3, 0, 0), []byte{0, 0, 0, 0, 0, 0, 0xFF, 0xFF}, make([]byte, 6)),
+		biffRecord(0x0207, biffString("公式")),
+		biffRecord(0x0205, u16(3, 1, 0), []byte{1, 0}),
+		biffRecord(0x0006, u16(3, 2, 0), f64(3.14), make([]byte, 6)),
+		biffRecord(0x0204, u16(4, 0, 0), biffString("label")),
+		biffRecord(0x0205, u16(4, 1, 0), []byte{0x07, 1}),
+		labelSST(5, 0, 5),
+		biffRecord(0x00E5, u16(1), u16(5, 5, 0, 2)),
+		eof,
+	}
+	sheet2 := [][]byte{bof(0x0010), labelSST(0, 0, 0), labelSST(1, 0, 6), labelSST(2, 0, 7), eof}
+	chart := [][]byte{bof(0x0020), eof}
+
+	join := func(records [][]byte) []byte {
+		b := []byte{}
+		for _, r := range records {
+			b = append(b, r...)
+		}
+		return b
+	}
+
+	stream := join(globals)
+	offsets := []int{len(stream)}
+	stream = append(stream, join(sheet1)...)
+	offsets = append(offsets, len(stream))
+	stream = append(stream, join(chart)...)
+	offsets = append(offsets, len(stream))
+	stream = append(stream, join(sheet2)...)
+
+	// patch the stream offsets of the BOUNDSHEET records
+	pos, i := 0, 0
+	for pos+4 <= len(stream) && i < len(offsets) {
+		id := binary.LittleEndian.Uint16(stream[pos:])
+		size := int(binary.LittleEndian.Uint16(stream[pos+2:]))
+		if id == 0x0085 {
+			binary.LittleEndian.PutUint32(stream[pos+4:], uint32(offsets[i]))
+			i++
+		}
+		pos += 4 + size
+	}
+
+	if len(stream) < 4096 {
+		stream = append(stream, make([]byte, 4096-len(stream))...)
+	}
+	return stream
+}
+
+// buildCFB wraps a Workbook stream into a minimal compound file
+func buildCFB(stream []byte) []byte {
+	const (
+		sectorSize = 512
+		endOfChain = 0xFFFFFFFE
+		freeSect   = 0xFFFFFFFF
+		noStream   = 0xFFFFFFFF
+	)
+	sectors := (len(stream) + sectorSize - 1) / sectorSize
+
+	header := make([]byte, sectorSize)
+	copy(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	copy(header[24:], u16(0x003E, 0x0003, 0xFFFE, 0x0009, 0x0006))
+	copy(header[44:], u32(1))
+	copy(header[48:], u32(1))
+	copy(header[56:], u32(4096))
+	binary.LittleEndian.PutUint32(header[60:], endOfChain)
+	binary.LittleEndian.PutUint32(header[68:], endOfChain)
+	for i := 0; i < 109; i++ {
+		binary.LittleEndian.PutUint32(header[76+i*4:], freeSect)
+	}
+	binary.LittleEndian.PutUint32(header[76:], 0)
+
+	fat := make([]byte, sectorSize)
+	for i := 0; i < sectorSize/4; i++ {
+		binary.LittleEndian.PutUint32(fat[i*4:], freeSect)
+	}
+	binary.LittleEndian.PutUint32(fat[0:], 0xFFFFFFFD)
+	binary.LittleEndian.PutUint32(fat[4:], endOfChain)
+	for i := 0; i < sectors; i++ {
+		next := uint32(3 + i)
+		if i == sectors-1 {
+			next = endOfChain
+		}
+		binary.LittleEndian.PutUint32(fat[(2+i)*4:], next)
+	}
+
+	dir := make([]byte, sectorSize)
+	entry := func(i int, name string, typ byte, child, start uint32, size int) {
+		e := dir[i*128:]
+		for j, u := range utf16.Encode([]rune(name)) {
+			binary.LittleEndian.PutUint16(e[j*2:], u)
+		}
+		if name != "" {
+			binary.LittleEndian.PutUint16(e[64:], uint16((len(name)+1)*2))
+		}
+		e[66], e[67] = typ, 1
+		binary.LittleEndian.PutUint32(e[68:], noStream)
+		binary.LittleEndian.PutUint32(e[72:], noStream)
+		binary.LittleEndian.PutUint32(e[76:], child)
+		binary.LittleEndian.PutUint32(e[116:], start)
+		binary.LittleEndian.PutUint32(e[120:], uint32(size))
+	}
+	entry(0, "Root Entry", 5, 1, endOfChain, 0)
+	entry(1, "Workbook", 2, noStream, 2, len(stream))
+	entry(2, "", 0, noStream, 0, 0)
+	entry(3, "", 0, noStream, 0, 0)
+
+	b := append(append(append(header, fat...), dir...), stream...)
+	if pad := len(b) % sectorSize; pad > 0 {
+		b = append(b, make([]byte, sectorSize-pad)...)
+	}
+	return b
+}
+
+func TestReadXLS(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	testFile := "./testdata/test_legacy.dat"
+	defer os.Remove(testFile)
+	tt.NoError(zfile.WriteFile(testFile, buildCFB(buildXLS())))
+
+	tt.Equal(xlsx.FormatXLS, xlsx.DetectFormat(testFile))
+
+	f, err := xlsx.Open(testFile)
+	tt.NoError(err)
+	defer f.Close()
+
+	sheets := f.Sheets()
+	tt.Equal(2, len(sheets))
+	tt.Equal("数据", sheets[0].Name)
+	tt.Equal("Hidden", sheets[1].Name)
+	tt.Equal(xlsx.SheetHidden, sheets[1].Visible)
+
+	data, err := f.Read()
+	tt.NoError(err)
+	tt.Log(data)
+	tt.Equal(5, len(data))
+	tt.Equal("张三", data[0].Get("name").String())
+	tt.Equal(95.5, data[0].Get("score").Float64())
+	tt.Equal("2025-01-01", data[0].Get("date").String())
+	tt.Equal("hello world", data[1].Get("name").String())
+	tt.Equal(80, data[1].Get("score").Int())
+	tt.Equal("公式", data[2].Get("name").String())
+	tt.Equal("TRUE", data[2].Get("score").String())
+	tt.Equal(3.14, data[2].Get("date").Float64())
+	tt.Equal("label", data[3].Get("name").String())
+	tt.Equal("#DIV/0!", data[3].Get("score").String())
+	tt.Equal("rich", data[4].Get("name").String())
+
+	date, ok := f.Get("数据", "C3").Value().(time.Time)
+	tt.EqualTrue(ok)
+	tt.Equal("2025-01-02", date.Format(time.DateOnly))
+
+	merges, err := f.Engine().GetMergeCells("数据")
+	tt.NoError(err)
+	tt.Equal(1, len(merges))
+	tt.Equal("A6", merges[0].GetStartAxis())
+	tt.Equal("C6", merges[0].GetEndAxis())
+
+	data, err = xlsx.Read(testFile, func(ro *xlsx.ReadOptions) {
+		ro.Sheet = "Hidden"
+	})
+	tt.NoError(err)
+	tt.Equal(2, len(data))
+	tt.Equal("y", data[1].Get("name").String())
+
+	header, _, err := xlsx.Load(testFile, "")
+	tt.NoError(err)
+	tt.Equal([]string{"name", "score", "date"}, header)
+}
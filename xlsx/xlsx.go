@@ -2,6 +2,8 @@ package xlsx
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/sohaha/zlsgo/zarray"
@@ -27,8 +29,23 @@ type ReadOptions struct {
 	RemoveEmptyRow      bool
 	TrimSpace           bool
 	HeaderMaps          map[string]string
+	// ImageFields returns the picture of the cells of the fields as Image
+	ImageFields []string
+	// WithHyperlinks and WithComments add the hyperlink target and the
+	// Comment of cells under the field key with LinkKeySuffix and CommentKeySuffix
+	WithHyperlinks bool
+	WithComments   bool
+	// HeaderRows is the number of header rows, grouped headers are joined
+	// into keys with HeaderSeparator, "." by default
+	HeaderRows      int
+	HeaderSeparator string
+	Delimiter       rune
 
 	excelize.Options
+
+	// lastRow and lastCol bound the rows and columns of the sheet that are read
+	lastRow int
+	lastCol int
 }
 
 // Read read xlsx file
@@ -49,6 +66,73 @@ type (
 		First       []string
 		Last        []string
 		CellHandler func(sheet string, cell string, value interface{}) ([]RichText, int)
+		// CellHandlerV2 sees the field and row of every header and data cell
+		// and may change its value, style, rich text, hyperlink and comment,
+		// an error aborts the write
+		CellHandlerV2 func(ctx CellContext) (CellResult, error)
+		// CommentHandler returns the comment of a data cell, nil for none
+		CommentHandler func(sheet string, cell string, field string, value interface{}) *Comment
+		// Columns declares the label, width and format of columns, declared
+		// fields are written first in the given order when First is empty
+		Columns []ColumnSpec
+		// MinWidth and MaxWidth bound the widths computed by AutoWidth
+		MinWidth float64
+		MaxWidth float64
+		// NilValue is written in place of nil values, empty cells by default
+		NilValue interface{}
+		// DateFormats overrides the number format of time values by field
+		DateFormats    map[string]string
+		DateFormat     string
+		DateTimeFormat string
+		// Delimiter separates the fields of csv output (comma by default), tsv is always tab separated
+		Delimiter rune
+		// BOM prefixes csv and tsv output with a UTF-8 BOM
+		BOM                bool
+		StringifyLargeInts bool
+		// HeaderStyle is applied to every header row
+		HeaderStyle *excelize.Style
+		// HeaderSeparator splits the labels into grouped header rows, such as
+		// 2025.Q1.Revenue with ".", groups are merged across their columns
+		HeaderSeparator string
+		// ZebraColor is the fill of the striped rows, DefaultZebraColor by default
+		ZebraColor string
+		// AutoWidth fits the column widths to the header and the first rows
+		AutoWidth bool
+		// FreezeHeader keeps the header row visible while scrolling
+		FreezeHeader bool
+		// ImageFields embeds the values of the fields as images scaled to
+		// their cells, values are file paths, []byte or Image
+		ImageFields []string
+		// ImageHeight is the height of rows with images, DefaultImageHeight by default
+		ImageHeight float64
+		// Validations restricts the values of fields below the header
+		Validations map[string]Validation
+		// Formulas writes a formula in every data row of the fields, templates
+		// such as =C{row}*D{row} or =SUM({col:amount}2:{col:amount}{last})
+		// are expanded with the column letters of the written fields
+		Formulas map[string]string
+		// Summary writes formula templates in a row below the data
+		Summary map[string]string
+		// SummaryLabel is written in the first column of the summary row
+		SummaryLabel string
+		// ConditionalFormats highlights the data cells of fields
+		ConditionalFormats map[string][]ConditionalFormat
+		// Charts adds charts over the written data
+		Charts []ChartSpec
+		// Pivots adds pivot tables over the written data on new sheets
+		Pivots []PivotSpec
+		// GroupBy writes static summaries of the data grouped by fields on new
+		// sheets, for tools that cannot refresh pivot tables
+		GroupBy []GroupSpec
+		// AsTable writes the data as an Excel table, AutoFilter is implied
+		AsTable *TableOptions
+		// Protect protects the sheet, leaving the columns not declared Locked
+		// open for input
+		Protect *Protection
+		// AutoFilter adds filter buttons to the header row
+		AutoFilter bool
+		// ZebraStripes shades every other data row
+		ZebraStripes bool
 	}
 )
 
@@ -58,67 +142,170 @@ func write(f *excelize.File, data ztype.Maps, opt ...func(*WriteOptions)) error
 		return errors.New("no data")
 	}
 
-	o := zutil.Optional(WriteOptions{Sheet: "Sheet1"}, opt...)
-	header := zarray.SortWithPriority(zarray.Keys(data[0]), o.First, o.Last)
+	o := zutil.Optional(WriteOptions{
+		Sheet:          "Sheet1",
+		DateFormat:     DefaultDateFormat,
+		DateTimeFormat: DefaultDateTimeFormat,
+	}, opt...)
+	header := sortHeader(data[0], o)
 	headerSize := len(header)
 
+	if err := CheckSheetName(o.Sheet); err != nil {
+		return err
+	}
+
 	index, err := f.NewSheet(o.Sheet)
 	if err != nil {
 		return err
 	}
 	f.SetActiveSheet(index)
 
-	err = f.SetSheetRow(o.Sheet, "A1", &header)
+	styles := newNumFmtStyles(f)
+	if err = applyColumns(f, o.Sheet, header, o, styles); err != nil {
+		return err
+	}
+
+	labels := headerLabels(header, o)
+	grid, err := writeHeader(f, o.Sheet, labels, o)
 	if err != nil {
 		return err
 	}
+	headerRows := len(grid)
 
-	if o.CellHandler != nil {
-		for i := range header {
-			cell := ToCol(i) + "1"
-			richTextRuns, styleID := o.CellHandler(o.Sheet, cell, header[i])
-			if styleID > 0 {
-				_ = f.SetCellStyle(o.Sheet, cell, cell, styleID)
-			}
-			if richTextRuns == nil {
-				continue
-			}
-			excelizeRuns := make([]excelize.RichTextRun, len(richTextRuns))
-			for i, rt := range richTextRuns {
-				excelizeRuns[i] = excelize.RichTextRun(rt)
-			}
-			f.SetCellRichText(o.Sheet, cell, excelizeRuns)
-		}
+	if err = applyHeaderPresets(f, o.Sheet, headerSize, headerRows, len(data), o); err != nil {
+		return err
+	}
+	if err = applyValidations(f, o.Sheet, header, headerRows, o); err != nil {
+		return err
+	}
+	if err = applyConditionalFormats(f, o.Sheet, header, headerRows, len(data), o); err != nil {
+		return err
+	}
+
+	if err = handleHeader(f, o.Sheet, header, grid, styles, o); err != nil {
+		return err
 	}
 
+	values := make([]cellWrite, headerSize)
+	results := make([]CellResult, headerSize)
 	for i := range data {
+		row := i + headerRows + 1
 		value := make([]interface{}, 0, headerSize)
 		for j := range header {
 			value = append(value, data[i][header[j]])
-		}
-		f.SetSheetRow(o.Sheet, "A"+strconv.Itoa(i+2), &value)
-		if o.CellHandler != nil {
-			for j := range value {
-				cell := ToCol(j) + strconv.Itoa(i+2)
-				richTextRuns, styleID := o.CellHandler(o.Sheet, cell, value[j])
-				if styleID > 0 {
-					_ = f.SetCellStyle(o.Sheet, cell, cell, styleID)
+			if o.CellHandlerV2 != nil {
+				results[j], err = handleCell(CellContext{
+					Value: value[j],
+					Row:   data[i],
+					Sheet: o.Sheet,
+					Cell:  ToCol(j) + strconv.Itoa(row),
+					Field: header[j],
+					Index: i,
+				}, o)
+				if err != nil {
+					return err
+				}
+				value[j] = results[j].value(value[j])
+			}
+			if _, ok := o.Formulas[header[j]]; ok || zarray.Contains(o.ImageFields, header[j]) {
+				if results[j].Value != nil || results[j].Link != nil {
+					return fmt.Errorf("%s!%s: CellResult cannot replace the value of formula or image field %s", o.Sheet, ToCol(j)+strconv.Itoa(row), header[j])
 				}
-				if richTextRuns == nil {
-					continue
+				values[j] = cellWrite{}
+				continue
+			}
+			values[j] = writeValue(header[j], value[j], &o)
+		}
+		if err = setRow(f, o.Sheet, row, values, styles); err != nil {
+			return err
+		}
+		if err = setRowFormulas(f, o.Sheet, header, o.Formulas, row, headerRows+1, headerRows+len(data)); err != nil {
+			return err
+		}
+		if len(o.ImageFields) > 0 {
+			if err = setRowImages(f, o.Sheet, header, data[i], row, o); err != nil {
+				return err
+			}
+		}
+		if o.CommentHandler != nil {
+			if err = setRowComments(f, o.Sheet, header, value, results, row, o); err != nil {
+				return err
+			}
+		}
+		for j := range value {
+			cell := ToCol(j) + strconv.Itoa(row)
+			if o.CellHandlerV2 != nil {
+				if err = results[j].apply(f, o.Sheet, cell, j, false, styles, o); err != nil {
+					return err
 				}
-				excelizeRuns := make([]excelize.RichTextRun, len(richTextRuns))
-				for i, rt := range richTextRuns {
-					excelizeRuns[i] = excelize.RichTextRun(rt)
+			}
+			if o.CellHandler != nil {
+				if err = applyCellHandler(f, o.Sheet, cell, value[j], j, false, styles, o); err != nil {
+					return err
 				}
-				f.SetCellRichText(o.Sheet, cell, excelizeRuns)
 			}
 		}
 	}
 
+	if err = addSummaryRow(f, o.Sheet, header, headerRows+1, headerRows+len(data), o); err != nil {
+		return err
+	}
+
+	if o.AsTable != nil {
+		if err = addTable(f, o.Sheet, header, labels, 1, len(data), *o.AsTable); err != nil {
+			return err
+		}
+	}
+
+	if len(o.Charts) > 0 {
+		if err = addCharts(f, o.Sheet, header, labels, headerRows, len(data), o); err != nil {
+			return err
+		}
+	}
+
+	if len(o.Pivots) > 0 {
+		if err = addPivots(f, o.Sheet, header, labels, headerRows, len(data), o); err != nil {
+			return err
+		}
+	}
+
+	if len(o.GroupBy) > 0 {
+		if err = addGroups(f, data, o); err != nil {
+			return err
+		}
+		f.SetActiveSheet(index)
+	}
+
+	if o.AutoWidth {
+		if err = autoWidth(f, o.Sheet, header, labels, headerRows, len(data), o); err != nil {
+			return err
+		}
+	}
+
+	if o.Protect != nil {
+		return protect(f, o.Sheet, *o.Protect)
+	}
 	return nil
 }
 
+// sortHeader returns the keys of the row in a stable order, honoring First
+// (or Columns without First) and Last, declared columns and formulas missing from the row are included
+func sortHeader(row ztype.Map, o WriteOptions) []string {
+	keys := zarray.Keys(row)
+	for _, c := range o.Columns {
+		if _, ok := row[c.Field]; !ok && !zarray.Contains(keys, c.Field) {
+			keys = append(keys, c.Field)
+		}
+	}
+	for _, field := range formulaFields(o) {
+		if _, ok := row[field]; !ok && !zarray.Contains(keys, field) {
+			keys = append(keys, field)
+		}
+	}
+	sort.Strings(keys)
+	return zarray.SortWithPriority(keys, columnFields(o), o.Last)
+}
+
 func (x *Xlsx) Write(data ztype.Maps, opt ...func(*WriteOptions)) ([]byte, error) {
 	err := write(x.f, data, opt...)
 	if err != nil {
@@ -140,19 +327,40 @@ func (x *Xlsx) WriteFile(path string, data ztype.Maps, opt ...func(*WriteOptions
 	}
 
 	if path != "" {
-		return x.f.SaveAs(path)
+		return x.SaveAs(path)
 	}
 
 	if x.path != "" {
+		if FormatOf(x.path) == FormatODS {
+			return x.SaveAs(x.path)
+		}
 		return zfile.WriteFile(x.path, b)
 	}
 
 	return x.f.Save()
 }
 
-// WriteFile write xlsx file
+// SaveAs saves the workbook, as an OpenDocument spreadsheet when the path ends with .ods
+func (x *Xlsx) SaveAs(path string) error {
+	if FormatOf(path) != FormatODS {
+		return x.f.SaveAs(path)
+	}
+
+	b, err := x.encodeODS()
+	if err != nil {
+		return err
+	}
+	return zfile.WriteFile(path, b)
+}
+
+// WriteFile write xlsx file, or an OpenDocument spreadsheet when the path ends with .ods
 func WriteFile(path string, data ztype.Maps, opt ...func(*WriteOptions)) error {
-	b, err := Write(data, opt...)
+	encode := Write
+	if FormatOf(path) == FormatODS {
+		encode = WriteODS
+	}
+
+	b, err := encode(data, opt...)
 	if err != nil {
 		return err
 	}
@@ -175,6 +383,18 @@ func Write(data ztype.Maps, opt ...func(*WriteOptions)) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+// WriteODS write the data as an OpenDocument spreadsheet
+func WriteODS(data ztype.Maps, opt ...func(*WriteOptions)) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+	err := write(f, data, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	return (&Xlsx{f: f}).encodeODS()
+}
+
 func (x *Xlsx) NewStyle(style *excelize.Style) (int, error) {
 	return x.f.NewStyle(style)
 }